@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/canvas"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/gormgen"
+)
+
+func exportTables(model *db.Model, opts canvas.Options) (tables []db.TableInfo) {
+	for _, info := range model.Tables {
+		if info.Kind == db.KindView || info.Kind == db.KindMaterializedView {
+			if !opts.Views {
+				continue
+			}
+		}
+		if info.PartitionOf != nil && !opts.ExpandPartitions {
+			continue
+		}
+		tables = append(tables, info)
+	}
+	return
+}
+
+func writeGoModels(dir string, tables []db.TableInfo) (err error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	files, err := gormgen.Generate(gormgen.PackageName(filepath.Base(abs)), tables)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return
+	}
+	for fn, src := range files {
+		err = os.WriteFile(filepath.Join(dir, fn), src, 0644)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	svg "github.com/ajstarks/svgo"
 )
@@ -18,63 +19,131 @@ type relation struct {
 }
 
 type Canvas struct {
-	groups  []*relation
-	bgStyle string
+	options      Options
+	groups       []*relation
+	enums        map[string]bool
+	bgStyle      string
+	clusterStyle string
+	clusterFont  string
+	captionFont  string
+	caption      string
 }
 
-func NewCanvas() *Canvas {
+func NewCanvas(options Options) *Canvas {
 	return &Canvas{
-		groups: []*relation{},
+		options: options,
+		groups:  []*relation{},
+		enums:   map[string]bool{},
 		bgStyle: StyleMap{
 			"fill":   "white",
 			"stroke": "none",
 		}.String(),
+		clusterStyle: StyleMap{
+			"fill":             "none",
+			"stroke":           "gray",
+			"stroke-dasharray": "8 4",
+		}.String(),
+		clusterFont: StyleMap{
+			"fill":        "gray",
+			"stroke":      "none",
+			"font-family": "monospace",
+			"font-size":   "20px",
+			"font-weight": "bold",
+		}.String(),
+		captionFont: StyleMap{
+			"fill":        "gray",
+			"stroke":      "none",
+			"font-family": "monospace",
+			"font-size":   "16px",
+		}.String(),
 	}
 }
 
+func (c *Canvas) SetCaption(caption string) {
+	c.caption = caption
+}
+
 func (c *Canvas) RegisterEntity(e *Entity) {
+	if e.isView() && !c.options.Views {
+		return
+	}
+	if e.isPartition && !c.options.ExpandPartitions {
+		return
+	}
 	c.groups = append(c.groups, &relation{
 		entity: e,
 	})
+
+	for _, en := range e.enums {
+		key := en.Schema + "." + en.Name
+		if !c.enums[key] {
+			c.enums[key] = true
+			c.RegisterEntity(NewEnumEntity(en))
+		}
+	}
 }
 
 func (c *Canvas) linkage() {
+	entities := map[string]*relation{}
 	for _, g := range c.groups {
 		g.use = false
 		g.left = g.left[:0]
 		g.right = g.right[:0]
+		entities[g.entity.key()] = g
 	}
 	for _, g := range c.groups {
-		for _, row := range g.entity.rows {
-			rel := row.relationaly
-			for _, g2 := range c.groups {
-				if rel.schema == g2.entity.schema && rel.table == g2.entity.name {
+		e := g.entity
+		for _, rel := range e.relations {
+			rel.target = nil
+			rel.drawn = false
+			if g2, ok := entities[rel.key()]; ok {
+				rel.target = g2.entity
+				if g2.entity.schema == e.schema {
 					g.right = append(g.right, g2)
 					g2.left = append(g2.left, g)
 				}
 			}
 		}
+
+		rebuild := false
+		for _, r := range e.rows {
+			refs := []string{}
+			for _, rel := range r.relations {
+				if rel.target == nil && rel.rows[0] == r {
+					refs = append(refs, "→ "+rel.fullname())
+				}
+			}
+			ref := strings.Join(refs, " ")
+			if r.reference.nm != ref {
+				r.reference.nm = ref
+				rebuild = true
+			}
+		}
+		if rebuild {
+			e.Build()
+		}
 	}
 }
 
 type singleNodesInfo struct {
+	id    string
 	w, h  int
 	group []*relation
 }
 
 func (n *singleNodesInfo) draw(s *svg.SVG, dx, dy int, space int) {
-	id := "single-nodes"
 	s.Def()
-	s.Gid(id)
+	s.Gid(n.id)
 	x := 0
 	for _, g := range n.group {
 		e := g.entity
 		e.Draw(s, x, 0)
+		e.origin = Point{dx + x, dy}
 		x += e.view.w + space
 	}
 	s.Gend()
 	s.DefEnd()
-	s.Use(dx, dy, "#"+id)
+	s.Use(dx, dy, "#"+n.id)
 }
 
 func (c *Canvas) extractSingle(space int) (singleNodes *singleNodesInfo) {
@@ -103,7 +172,7 @@ func (c *Canvas) extractSingle(space int) (singleNodes *singleNodesInfo) {
 		}
 	}
 
-	return &singleNodesInfo{w, h, group}
+	return &singleNodesInfo{w: w, h: h, group: group}
 }
 
 type levelBox struct {
@@ -114,16 +183,14 @@ type levelBox struct {
 }
 
 type regionInfo struct {
+	id     string
 	w, h   int
 	levels []*levelBox
 }
 
-var seq int = 0
-
 func (ri *regionInfo) draw(s *svg.SVG, dx, dy int, space int) {
-	id := fmt.Sprintf("region-%d", seq)
 	s.Def()
-	s.Gid(id)
+	s.Gid(ri.id)
 	levels := ri.levels
 	size := len(levels)
 	x := 0
@@ -135,11 +202,13 @@ func (ri *regionInfo) draw(s *svg.SVG, dx, dy int, space int) {
 			e := g.entity
 			ml1 := (lvl.w + space - e.view.w) / 2
 			e.Draw(s, x+ml1, y+half)
-			for _, r := range e.rows {
-				if r.relationaly.valid() {
-					x1 := ml1 + x + r.frame.x + r.frame.w
-					y1 := half + y + r.frame.y + r.frame.h>>1
-					rnm := r.relationaly.fullname()
+			e.origin = Point{dx + x + ml1, dy + y + half}
+			for _, rel := range e.relations {
+				src := rel.source()
+				dst := rel.destination()
+				if src != nil && dst != nil {
+					x1 := ml1 + x + src.x + src.w
+					y1 := half + y + src.y + src.h>>1
 					nx := x + lvl.w + space
 					hh := 0
 
@@ -153,12 +222,11 @@ func (ri *regionInfo) draw(s *svg.SVG, dx, dy int, space int) {
 						for _, rg := range curlvl.g {
 							re := rg.entity
 							ml2 := (curlvl.w + space - re.view.w) / 2
-							c := re.collision[rnm]
-							if c != nil {
-								x2 := ml2 + nx + c.x
-								y2 := half + ny + c.y + c.h>>1
+							if re == rel.target {
+								x2 := ml2 + nx + dst.x
+								y2 := half + ny + dst.y + dst.h>>1
 								if cpx == nx {
-									s.Bezier(x1, y1, cpx, y1, nx, y2, x2, y2, e.lineStyle)
+									s.Bezier(x1, y1, cpx, y1, nx, y2, x2, y2, rel.style)
 								} else {
 									hhh := 0
 									cy := 0
@@ -170,10 +238,13 @@ func (ri *regionInfo) draw(s *svg.SVG, dx, dy int, space int) {
 									if cy > hh/2 {
 										hhh = hh
 									}
-									s.Bezier(x1, y1, cpx, y1, cpx, hhh, cpx+half, hhh, e.lineStyle)
-									s.Bezier(x2, y2, nx, y2, nx, hhh, nx-half, hhh, e.lineStyle)
-									s.Line(cpx+half, hhh, nx-half, hhh, e.lineStyle)
+									s.Bezier(x1, y1, cpx, y1, cpx, hhh, cpx+half, hhh, rel.style)
+									s.Bezier(x2, y2, nx, y2, nx, hhh, nx-half, hhh, rel.style)
+									s.Line(cpx+half, hhh, nx-half, hhh, rel.style)
 								}
+								rel.drawMarker(s, x1, y1, 1)
+								rel.drawHead(s, x2, y2, 1)
+								rel.drawn = true
 								break search
 							}
 							ny += re.view.h + space
@@ -188,7 +259,7 @@ func (ri *regionInfo) draw(s *svg.SVG, dx, dy int, space int) {
 	}
 	s.Gend()
 	s.DefEnd()
-	s.Use(dx, dy, "#"+id)
+	s.Use(dx, dy, "#"+ri.id)
 }
 
 func (c *Canvas) extractRegion(space int) (region *regionInfo) {
@@ -201,38 +272,43 @@ func (c *Canvas) extractRegion(space int) (region *regionInfo) {
 	base := c.groups[0]
 	base.use = true
 
+	var visited map[*relation]bool
 	var left func(*relation, int)
 	var right func(*relation, int)
 
 	left = func(c *relation, o int) {
+		visited[c] = true
 		c.use = true
 		c.offset = o
 		for _, l := range c.left {
-			if !l.use || l.offset > o-1 {
+			if !visited[l] && (!l.use || l.offset > o-1) {
 				left(l, o-1)
 			}
 		}
 		for _, r := range c.right {
-			if !r.use || r.offset < o+1 {
+			if !visited[r] && (!r.use || r.offset < o+1) {
 				right(r, o+1)
 			}
 		}
 	}
 	right = func(c *relation, o int) {
+		visited[c] = true
 		c.use = true
 		c.offset = o
 		for _, r := range c.right {
-			if !r.use || r.offset < o+1 {
+			if !visited[r] && (!r.use || r.offset < o+1) {
 				right(r, o+1)
 			}
 		}
 		for _, l := range c.left {
-			if !l.use || l.offset > o-1 {
+			if !visited[l] && (!l.use || l.offset > o-1) {
 				left(l, o-1)
 			}
 		}
 	}
+	visited = map[*relation]bool{}
 	left(base, 0)
+	visited = map[*relation]bool{}
 	right(base, 0)
 
 	l := map[int][]*relation{}
@@ -267,52 +343,178 @@ func (c *Canvas) extractRegion(space int) (region *regionInfo) {
 
 	sort.Slice(levels, func(i, j int) bool { return levels[i].lv < levels[j].lv })
 
-	return &regionInfo{w + space, h + space, levels}
+	return &regionInfo{w: w + space, h: h + space, levels: levels}
+}
+
+type cluster struct {
+	schema  string
+	w, h    int
+	singles *singleNodesInfo
+	regions []*regionInfo
+}
+
+func (cl *cluster) draw(s *svg.SVG, dx, dy int, space int) {
+	y := dy
+	if cl.singles != nil {
+		cl.singles.draw(s, dx, y, space)
+		y += cl.singles.h + space
+	}
+	for _, region := range cl.regions {
+		region.draw(s, dx, y, space)
+		y += region.h + space
+	}
+}
+
+func (c *Canvas) extractClusters(space int) (clusters []*cluster) {
+	schemas := []string{}
+	groups := map[string][]*relation{}
+	for _, g := range c.groups {
+		schema := g.entity.schema
+		if _, ok := groups[schema]; !ok {
+			schemas = append(schemas, schema)
+		}
+		groups[schema] = append(groups[schema], g)
+	}
+	sort.Strings(schemas)
+
+	for _, schema := range schemas {
+		sub := &Canvas{groups: groups[schema]}
+		cl := &cluster{schema: schema}
+
+		cl.singles = sub.extractSingle(space)
+		if cl.singles != nil {
+			cl.singles.id = fmt.Sprintf("single-nodes-%s", schema)
+			cl.w = cl.singles.w
+			cl.h = cl.singles.h
+		}
+		for {
+			r := sub.extractRegion(space)
+			if r == nil {
+				break
+			}
+			r.id = fmt.Sprintf("region-%s-%d", schema, len(cl.regions))
+			cl.regions = append(cl.regions, r)
+			if cl.w < r.w {
+				cl.w = r.w
+			}
+			cl.h += space + r.h
+		}
+
+		clusters = append(clusters, cl)
+	}
+
+	return
+}
+
+func (r *relationaly) drawMarker(s *svg.SVG, x, y, dir int) {
+	radius := 4
+	if r.oneToOne {
+		bx := x + dir*radius*3
+		s.Line(bx, y-radius*2, bx, y+radius*2, r.style)
+	}
+	if len(r.marker) > 0 {
+		s.Circle(x+dir*radius, y, radius, r.marker)
+	}
+}
+
+func (r *relationaly) drawHead(s *svg.SVG, x, y, dir int) {
+	if len(r.head) == 0 {
+		return
+	}
+	size := 6
+	bx := x - dir*size*2
+	s.Polygon([]int{x, bx, bx}, []int{y, y - size, y + size}, r.head)
+}
+
+func (c *Canvas) drawLinks(s *svg.SVG, space int) {
+	d := space >> 1
+	for _, g := range c.groups {
+		e := g.entity
+		for _, rel := range e.relations {
+			if rel.drawn {
+				continue
+			}
+			src := rel.source()
+			dst := rel.destination()
+			if src == nil || dst == nil {
+				continue
+			}
+
+			t := rel.target
+			left1 := e.origin.x + src.x
+			right1 := left1 + src.w
+			left2 := t.origin.x + dst.x
+			right2 := left2 + dst.w
+			y1 := e.origin.y + src.y + src.h>>1
+			y2 := t.origin.y + dst.y + dst.h>>1
+
+			if right1 <= left2 {
+				s.Bezier(right1, y1, right1+d, y1, left2-d, y2, left2, y2, rel.style)
+				rel.drawMarker(s, right1, y1, 1)
+				rel.drawHead(s, left2, y2, 1)
+			} else if right2 <= left1 {
+				s.Bezier(left1, y1, left1-d, y1, right2+d, y2, right2, y2, rel.style)
+				rel.drawMarker(s, left1, y1, -1)
+				rel.drawHead(s, right2, y2, -1)
+			} else {
+				cpx := max(right1, right2) + d
+				s.Bezier(right1, y1, cpx, y1, cpx, y2, right2, y2, rel.style)
+				rel.drawMarker(s, right1, y1, 1)
+				rel.drawHead(s, right2, y2, -1)
+			}
+			rel.drawn = true
+		}
+	}
 }
 
 func (c *Canvas) OutputSVG(o io.Writer) {
 	c.linkage()
 
 	space := 48
-	singleNodes := c.extractSingle(space)
-
-	regions := []*regionInfo{}
-	for {
-		r := c.extractRegion(space)
-		if r == nil {
-			break
-		}
-		regions = append(regions, r)
+	clusters := c.extractClusters(space)
+
+	framed := len(clusters) > 1
+	pad := 0
+	header := 0
+	if framed {
+		pad = space >> 1
+		header = space
 	}
 
 	w := 0
 	h := 0
-	if singleNodes != nil {
-		w = singleNodes.w
-		h = singleNodes.h
-	}
-	for i := range regions {
-		w2 := regions[i].w
-		h2 := regions[i].h
-		if w < w2 {
-			w = w2
+	for i, cl := range clusters {
+		if w < cl.w+pad*2 {
+			w = cl.w + pad*2
 		}
-		h += space + h2
+		if i > 0 {
+			h += space
+		}
+		h += header + cl.h + pad
+	}
+	if len(c.caption) > 0 {
+		w = max(w, (width(c.caption)+2)*8)
+		h += space
 	}
 
 	s := svg.New(o)
 	s.Start(w, h)
 	s.Rect(0, 0, w, h, c.bgStyle)
 
-	regionY := 0
-	if singleNodes != nil {
-		singleNodes.draw(s, 0, 0, space)
-		regionY += singleNodes.h + space
+	y := 0
+	for _, cl := range clusters {
+		if framed {
+			s.Rect(0, y, w, header+cl.h+pad, c.clusterStyle)
+			s.Text(pad, y+header-pad/2, cl.schema, c.clusterFont)
+		}
+		cl.draw(s, pad, y+header, space)
+		y += header + cl.h + pad + space
 	}
 
-	for _, region := range regions {
-		region.draw(s, 0, regionY, space)
-		regionY += region.h + space
+	c.drawLinks(s, space)
+
+	if len(c.caption) > 0 {
+		s.Text(8, h-space/2+8, c.caption, c.captionFont)
 	}
 
 	s.End()
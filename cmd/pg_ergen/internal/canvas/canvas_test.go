@@ -0,0 +1,135 @@
+package canvas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+)
+
+func loadDDL(t *testing.T, src string) (model db.Model) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	model, err := (&db.DDLSource{Path: path}).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func render(c *Canvas) string {
+	var sb strings.Builder
+	c.OutputSVG(&sb)
+	return sb.String()
+}
+
+func renderModel(model *db.Model, opts Options) string {
+	c := NewCanvas(opts)
+	for i := range model.Tables {
+		c.RegisterEntity(NewEntityFromTableInfo(&model.Tables[i], opts))
+	}
+	return render(c)
+}
+
+func TestOutputSVGHideInheritedEnums(t *testing.T) {
+	model := loadDDL(t, `
+		CREATE TYPE state AS ENUM ('on', 'off');
+		CREATE TABLE parent (id int PRIMARY KEY, state state);
+		CREATE TABLE child (extra text) INHERITS (parent);
+	`)
+
+	got := renderModel(&model, Options{HideInherited: true, Enums: true})
+	for _, want := range []string{"public.parent", "public.child", "public.state", "extra"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+}
+
+func TestOutputSVGForeignKeyCycle(t *testing.T) {
+	model := loadDDL(t, `
+		CREATE TABLE a (id int PRIMARY KEY, b_id int);
+		CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a);
+		ALTER TABLE a ADD FOREIGN KEY (b_id) REFERENCES b;
+		CREATE TABLE x (id int PRIMARY KEY, z_id int);
+		CREATE TABLE y (id int PRIMARY KEY, x_id int REFERENCES x);
+		CREATE TABLE z (id int PRIMARY KEY, y_id int REFERENCES y);
+		ALTER TABLE x ADD FOREIGN KEY (z_id) REFERENCES z;
+		CREATE TABLE tree (id int PRIMARY KEY, parent_id int REFERENCES tree);
+		CREATE TABLE leaf (id int PRIMARY KEY, a_id int REFERENCES a, tree_id int REFERENCES tree);
+	`)
+
+	got := renderModel(&model, Options{})
+	for _, want := range []string{"public.a", "public.b", "public.x", "public.y", "public.z", "public.tree", "public.leaf", "</svg>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+}
+
+func TestDiffCanvas(t *testing.T) {
+	from := loadDDL(t, `
+		CREATE TABLE users (id int PRIMARY KEY, name varchar(80), tags text[]);
+		CREATE TABLE legacy (id int PRIMARY KEY, user_id int REFERENCES users);
+	`)
+	to := loadDDL(t, `
+		CREATE TABLE users (id int PRIMARY KEY, name text, nickname text);
+		CREATE TABLE invoices (id int PRIMARY KEY, user_id int REFERENCES users, last_payment_id int);
+		CREATE TABLE payments (id int PRIMARY KEY, invoice_id int REFERENCES invoices);
+		ALTER TABLE invoices ADD FOREIGN KEY (last_payment_id) REFERENCES payments;
+	`)
+	d := diff.Compare(&from, &to)
+	c := NewDiffCanvas(&to, &d, Options{})
+
+	entities := map[string]*Entity{}
+	for _, g := range c.groups {
+		entities[g.entity.key()] = g.entity
+	}
+	tests := []struct {
+		table string
+		style string
+	}{
+		{"public.invoices", "stroke:green"},
+		{"public.payments", "stroke:green"},
+		{"public.legacy", "stroke:red"},
+	}
+	for _, tt := range tests {
+		e, ok := entities[tt.table]
+		if !ok {
+			t.Errorf("%s is not on the canvas", tt.table)
+		} else if !strings.Contains(e.frameStyle, tt.style) {
+			t.Errorf("%s frame style = %q, want %s", tt.table, e.frameStyle, tt.style)
+		}
+	}
+
+	users := entities["public.users"]
+	rows := []struct {
+		column   string
+		style    string
+		dataType string
+	}{
+		{"nickname", "fill:green", "text"},
+		{"name", "fill:darkorange", "varchar(80) → text"},
+		{"tags", "line-through", "text[]"},
+	}
+	for _, tt := range rows {
+		r := users.row(tt.column)
+		if r == nil {
+			t.Errorf("users.%s has no row", tt.column)
+			continue
+		}
+		if !strings.Contains(r.style, tt.style) || r.dataType.nm != tt.dataType {
+			t.Errorf("users.%s = %q %q, want %s %q", tt.column, r.style, r.dataType.nm, tt.style, tt.dataType)
+		}
+	}
+
+	if got := render(c); !strings.Contains(got, "</svg>") {
+		t.Errorf("diff SVG is incomplete:\n%s", got)
+	}
+}
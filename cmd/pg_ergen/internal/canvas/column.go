@@ -1,6 +1,8 @@
 package canvas
 
 import (
+	"strings"
+
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
 )
 
@@ -10,17 +12,65 @@ type column struct {
 }
 
 type relationaly struct {
-	schema string
-	table  string
-	column string
+	kind    string
+	name    string
+	schema  string
+	table   string
+	columns []string
+	rows    []*row
+	from    *Entity
+	style   string
+	marker  string
+	head    string
+
+	oneToOne bool
+
+	target *Entity
+	drawn  bool
 }
 
 func (r *relationaly) fullname() string {
-	return r.schema + "." + r.table + "." + r.column
+	if len(r.columns) == 1 {
+		return r.tablename() + "." + r.columns[0]
+	}
+	return r.tablename() + ".(" + strings.Join(r.columns, ", ") + ")"
+}
+
+func (r *relationaly) key() string {
+	if r.kind == kindEnum {
+		return kindEnum + ":" + r.tablename()
+	}
+	return r.tablename()
 }
 
-func (r *relationaly) valid() bool {
-	return len(r.schema) > 0 || len(r.table) > 0 || len(r.column) > 0
+func (r *relationaly) tablename() string {
+	return r.schema + "." + r.table
+}
+
+func (r *relationaly) source() (rect *Rectangle) {
+	if len(r.rows) == 0 {
+		return &r.from.frame
+	}
+	for _, row := range r.rows {
+		rect = rect.union(row.frame)
+	}
+	return
+}
+
+func (r *relationaly) destination() (rect *Rectangle) {
+	if r.target == nil {
+		return
+	}
+	if len(r.columns) == 0 {
+		return &r.target.frame
+	}
+	for _, c := range r.columns {
+		rect = rect.union(r.target.collision[r.target.fullname()+"."+c])
+	}
+	if rect == nil {
+		return &r.target.frame
+	}
+	return
 }
 
 type row struct {
@@ -32,25 +82,50 @@ type row struct {
 
 	notNull      Rectangle
 	physicalName column
+	marker       column
 	dataType     column
 	logicalName  column
+	reference    column
+	tooltip      string
+	style        string
 
-	relationaly relationaly
+	relations []*relationaly
 }
 
 type rows []*row
 
-func NewRow(c *db.Column) *row {
-	fk := c.ForeignKey
-	dt := c.DataType
-	rel := relationaly{}
-	if len(fk.ConstraintName) != 0 {
-		dt += "(FK)"
-		rel = relationaly{
-			schema: fk.TableSchema,
-			table:  fk.TableName,
-			column: fk.ColumnName,
+func (r *row) addTooltip(t string) {
+	if len(r.tooltip) > 0 {
+		r.tooltip += "\n"
+	}
+	r.tooltip += t
+}
+
+func (r *row) addMarker(m string) {
+	if len(r.marker.nm) > 0 {
+		r.marker.nm += " "
+	}
+	r.marker.nm += m
+}
+
+func NewRow(c *db.Column, opts Options) *row {
+	dt := c.FormatType(opts.TypeAbbreviations)
+	tooltip := ""
+	if c.Domain != nil {
+		if len(dt) == 0 {
+			dt = c.Domain.BaseType
 		}
+		dt = c.Domain.Name + " (" + dt + ")"
+		tooltip = domainLabel(c.Domain)
+	} else if c.Enum != nil {
+		tooltip = enumLabel(c.Enum)
+	}
+	if len(c.ForeignKeys) > 0 {
+		dt += "(FK)"
+	}
+	marker := ""
+	if c.IsInherited {
+		marker = inheritedMarker
 	}
 
 	nn := false
@@ -64,9 +139,9 @@ func NewRow(c *db.Column) *row {
 		isNotNull:    nn,
 
 		physicalName: column{nm: c.ColumnName},
+		marker:       column{nm: marker},
 		dataType:     column{nm: dt},
 		logicalName:  column{nm: c.Comment},
-
-		relationaly: rel,
+		tooltip:      tooltip,
 	}
 }
@@ -0,0 +1,147 @@
+package canvas
+
+import (
+	"fmt"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+)
+
+var diffColors = map[string]string{
+	diff.Added:   "green",
+	diff.Removed: "red",
+	diff.Changed: "darkorange",
+}
+
+func NewDiffCanvas(to *db.Model, d *diff.Diff, opts Options) *Canvas {
+	c := NewCanvas(opts)
+	for i := range to.Tables {
+		info := &to.Tables[i]
+		e := NewEntityFromTableInfo(info, opts)
+		if td := d.Table(info.Schema, info.Name); td != nil {
+			e.applyDiff(td, opts)
+		}
+		c.RegisterEntity(e)
+	}
+	for _, td := range d.Tables {
+		if td.Status == diff.Removed {
+			e := NewEntityFromTableInfo(td.Old, opts)
+			e.applyDiff(td, opts)
+			c.RegisterEntity(e)
+		}
+	}
+
+	return c
+}
+
+func diffFont(status string) StyleMap {
+	style := StyleMap{
+		"fill":   diffColors[status],
+		"stroke": "none",
+	}
+	if status == diff.Removed {
+		style["text-decoration"] = "line-through"
+	}
+	return style
+}
+
+func diffLine(status string) string {
+	return StyleMap{
+		"fill":             "none",
+		"stroke":           diffColors[status],
+		"stroke-dasharray": "6 3",
+	}.String()
+}
+
+func (e *Entity) applyDiff(td *diff.TableDiff, opts Options) {
+	if td.Status != diff.Changed {
+		font := diffFont(td.Status)
+		font["font-family"] = "monospace"
+		font["font-size"] = fmt.Sprintf("%dpx", e.height)
+		e.font = font.String()
+		e.typeFont = diffFont(td.Status).String()
+		e.frameStyle = StyleMap{
+			"fill":   "none",
+			"stroke": diffColors[td.Status],
+		}.String()
+		e.lineStyle = e.frameStyle
+		for _, rel := range e.relations {
+			if len(rel.name) > 0 {
+				rel.style = diffLine(td.Status)
+			}
+		}
+		e.Build()
+		return
+	}
+
+	if td.Comment != nil {
+		e.title += fmt.Sprintf(" [comment %q → %q]", td.Comment.Old, td.Comment.New)
+	}
+
+	for _, cd := range td.Columns {
+		switch cd.Status {
+		case diff.Added:
+			if r := e.row(cd.Name); r != nil {
+				r.style = diffFont(diff.Added).String()
+			}
+		case diff.Removed:
+			r := NewRow(cd.Old, opts)
+			r.isPrimaryKey = false
+			r.style = diffFont(diff.Removed).String()
+			e.rows = append(e.rows, r)
+		case diff.Changed:
+			r := e.row(cd.Name)
+			if r == nil {
+				continue
+			}
+			r.style = diffFont(diff.Changed).String()
+			if cd.Type != nil {
+				r.dataType.nm = cd.Type.Old + " → " + r.dataType.nm
+			}
+			if cd.Nullable != nil {
+				r.addTooltip("nullable: " + cd.Nullable.Old + " → " + cd.Nullable.New)
+			}
+			if cd.Comment != nil {
+				r.addTooltip("comment: " + cd.Comment.Old + " → " + cd.Comment.New)
+			}
+		}
+	}
+
+	for _, fd := range td.ForeignKeys {
+		switch fd.Status {
+		case diff.Added, diff.Changed:
+			for _, rel := range e.relations {
+				if rel.name == fd.Name {
+					rel.style = diffLine(fd.Status)
+				}
+			}
+		case diff.Removed:
+			fk := fd.Old
+			rel := &relationaly{
+				name:    fk.ConstraintName,
+				schema:  fk.TableSchema,
+				table:   fk.TableName,
+				columns: fk.ReferencedColumns,
+				from:    e,
+				style:   diffLine(diff.Removed),
+			}
+			for _, name := range fk.Columns {
+				if r := e.row(name); r != nil {
+					rel.rows = append(rel.rows, r)
+				}
+			}
+			e.relations = append(e.relations, rel)
+		}
+	}
+
+	e.Build()
+}
+
+func (e *Entity) row(name string) *row {
+	for _, r := range e.rows {
+		if r.physicalName.nm == name {
+			return r
+		}
+	}
+	return nil
+}
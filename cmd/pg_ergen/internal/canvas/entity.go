@@ -3,6 +3,7 @@ package canvas
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	svg "github.com/ajstarks/svgo"
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
@@ -11,11 +12,16 @@ import (
 type Entity struct {
 	schema  string
 	name    string
+	kind    string
 	comment string
 	rows    rows
 	pkeys   []int
 	field   []int
 
+	relations []*relationaly
+	footer    []*column
+	enums     []*db.Enum
+
 	margin int
 	width  int
 	height int
@@ -23,29 +29,84 @@ type Entity struct {
 
 	hasForeignKey bool
 	isChildren    bool
+	isPartition   bool
 	title         string
 	view          Rectangle
 	tiltePos      Point
 	frame         Rectangle
 	separateLine  TwoPointCoordinates
+	footerLine    TwoPointCoordinates
 	collision     map[string]*Rectangle
+	origin        Point
+
+	lineStyle       string
+	frameStyle      string
+	dependStyle     string
+	deferrableStyle string
+	deferredStyle   string
+	headStyle       string
+	font            string
+	typeFont        string
+}
+
+var stereotypes = map[string]string{
+	db.KindView:             "«view» ",
+	db.KindMaterializedView: "«materialized view» ",
+	db.KindForeignTable:     "«foreign» ",
+	kindEnum:                "«enum» ",
+}
 
-	lineStyle string
-	font      string
-	typeFont  string
+var frameDashes = map[string]string{
+	db.KindView:             "6 3",
+	db.KindMaterializedView: "2 2",
 }
 
-func NewEntity(schema string, name string, comment string) *Entity {
+func NewEntity(schema string, name string, kind string, comment string) *Entity {
 	height := 16
 
 	title := name
+	if schema != "public" {
+		title = schema + "." + name
+	}
 	if len(comment) > 0 {
-		title = fmt.Sprintf("%s (%s)", comment, name)
+		title = fmt.Sprintf("%s (%s)", comment, title)
+	}
+	title = stereotypes[kind] + title
+
+	lineStyle := StyleMap{
+		"fill":   "none",
+		"stroke": "black",
+	}
+	frameStyle := StyleMap{
+		"fill":   "none",
+		"stroke": "black",
+	}
+	if dash, ok := frameDashes[kind]; ok {
+		frameStyle["stroke-dasharray"] = dash
+	}
+	dependStyle := StyleMap{
+		"fill":             "none",
+		"stroke":           "gray",
+		"stroke-dasharray": "6 3",
+	}
+	deferrable := StyleMap{
+		"fill":   "white",
+		"stroke": "black",
+	}
+	deferred := StyleMap{
+		"fill":   "black",
+		"stroke": "black",
+	}
+
+	head := StyleMap{
+		"fill":   "white",
+		"stroke": "black",
 	}
 
 	return &Entity{
 		schema:  schema,
 		name:    name,
+		kind:    kind,
 		comment: comment,
 
 		margin: 2,
@@ -56,10 +117,12 @@ func NewEntity(schema string, name string, comment string) *Entity {
 		title:     title,
 		collision: map[string]*Rectangle{},
 
-		lineStyle: StyleMap{
-			"fill":   "none",
-			"stroke": "black",
-		}.String(),
+		lineStyle:       lineStyle.String(),
+		frameStyle:      frameStyle.String(),
+		dependStyle:     dependStyle.String(),
+		deferrableStyle: deferrable.String(),
+		deferredStyle:   deferred.String(),
+		headStyle:       head.String(),
 		font: StyleMap{
 			"fill":        "black",
 			"stroke":      "none",
@@ -70,15 +133,157 @@ func NewEntity(schema string, name string, comment string) *Entity {
 	}
 }
 
-func NewEntityFromTableInfo(ti *db.TableInfo) *Entity {
-	e := NewEntity(ti.Schema, ti.Name, ti.Comment)
+func NewEntityFromTableInfo(ti *db.TableInfo, opts Options) *Entity {
+	e := NewEntity(ti.Schema, ti.Name, ti.Kind, ti.Comment)
+	if len(ti.PartitionKey) > 0 {
+		e.title += fmt.Sprintf(" [%s, %d partitions]", ti.PartitionKey, len(ti.Partitions))
+	}
+	if ti.PartitionOf != nil {
+		e.isPartition = true
+		e.title = "«partition» " + e.title
+		e.footer = append(e.footer, &column{nm: ti.PartitionBound})
+		e.relations = append(e.relations, &relationaly{
+			schema: ti.PartitionOf.Schema,
+			table:  ti.PartitionOf.Name,
+			from:   e,
+			style:  e.dependStyle,
+		})
+	}
 
+	for _, parent := range ti.Inherits {
+		e.relations = append(e.relations, &relationaly{
+			kind:   kindInherits,
+			schema: parent.Schema,
+			table:  parent.Name,
+			from:   e,
+			style:  e.lineStyle,
+			head:   e.headStyle,
+		})
+	}
+
+	rows := map[string]*row{}
 	for _, col := range ti.Columns {
-		fk := col.ForeignKey
+		if col.IsInherited && opts.HideInherited {
+			continue
+		}
+		r := NewRow(col, opts)
+		rows[col.ColumnName] = r
+		e.rows = append(e.rows, r)
+	}
+
+	for _, fk := range ti.ForeignKeys {
 		if fk.UpdateRule == "CASCADE" || fk.DeleteRule == "CASCADE" {
 			e.isChildren = true
 		}
-		e.rows = append(e.rows, NewRow(col))
+		rel := &relationaly{
+			name:    fk.ConstraintName,
+			schema:  fk.TableSchema,
+			table:   fk.TableName,
+			columns: fk.ReferencedColumns,
+			from:    e,
+			style:   e.lineStyle,
+
+			oneToOne: ti.IsUnique(fk.Columns),
+		}
+		if fk.InitiallyDeferred {
+			rel.marker = e.deferredStyle
+		} else if fk.IsDeferrable {
+			rel.marker = e.deferrableStyle
+		}
+		for _, name := range fk.Columns {
+			if r, ok := rows[name]; ok {
+				rel.rows = append(rel.rows, r)
+				r.relations = append(r.relations, rel)
+			}
+		}
+		e.relations = append(e.relations, rel)
+	}
+
+	if len(ti.PrimaryKeyComment) > 0 {
+		for _, name := range ti.PrimaryKey {
+			if r, ok := rows[name]; ok {
+				r.addTooltip(keyLabel(ti.PrimaryKeyName, ti.PrimaryKeyComment))
+			}
+		}
+	}
+
+	for k, key := range ti.UniqueKeys {
+		for j, name := range key.Columns {
+			if r, ok := rows[name]; ok {
+				r.addMarker(keyMarker("AK", k+1, j, len(key.Columns)))
+				if len(key.Comment) > 0 {
+					r.addTooltip(keyLabel(key.Name, key.Comment))
+				}
+			}
+		}
+	}
+
+	switch opts.Indexes {
+	case IndexFooter:
+		for _, ix := range ti.Indexes {
+			if !ix.IsPrimary {
+				e.footer = append(e.footer, &column{nm: indexLabel(ix)})
+			}
+		}
+	case IndexMarker:
+		k := 0
+		for _, ix := range ti.Indexes {
+			if ix.IsPrimary || (ix.IsUnique && len(ix.Predicate) == 0) {
+				continue
+			}
+			k += 1
+			for j, name := range ix.Columns {
+				if r, ok := rows[name]; ok {
+					r.addMarker(keyMarker("IE", k, j, len(ix.Columns)))
+				}
+			}
+		}
+	}
+
+	if opts.Enums {
+		names := []string{}
+		for name := range ti.Columns {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			en := ti.Columns[name].Enum
+			r, ok := rows[name]
+			if en == nil || !ok {
+				continue
+			}
+			e.enums = append(e.enums, en)
+			e.relations = append(e.relations, &relationaly{
+				kind:   kindEnum,
+				schema: en.Schema,
+				table:  en.Name,
+				rows:   []*row{r},
+				from:   e,
+				style:  e.dependStyle,
+			})
+		}
+	}
+
+	for _, con := range ti.Constraints {
+		label := constraintLabel(con)
+		for _, name := range con.Columns {
+			if r, ok := rows[name]; ok {
+				r.addTooltip(label)
+			}
+		}
+		if opts.Constraints {
+			e.footer = append(e.footer, &column{nm: label})
+		}
+	}
+
+	for _, dep := range ti.Dependencies {
+		e.relations = append(e.relations, &relationaly{
+			schema: dep.Schema,
+			table:  dep.Name,
+			from:   e,
+			style:  e.dependStyle,
+		})
 	}
 	e.Build()
 
@@ -86,6 +291,10 @@ func NewEntityFromTableInfo(ti *db.TableInfo) *Entity {
 }
 
 func (e *Entity) Build() {
+	e.pkeys = nil
+	e.field = nil
+	e.collision = map[string]*Rectangle{}
+
 	sort.Slice(e.rows, func(i, j int) bool {
 		return e.rows[i].order < e.rows[j].order
 	})
@@ -113,11 +322,23 @@ func (e *Entity) Build() {
 		lnw = (cw.logicalName + 2) * w
 	}
 	pnw := (cw.physicalName + 2) * w
+	mkw := 0
+	if cw.marker != 0 {
+		mkw = (cw.marker + 1) * w
+	}
 	dtw := (cw.dataType + 2) * w
+	rfw := 0
+	if cw.reference != 0 {
+		rfw = (cw.reference + 2) * w
+	}
 
-	columnW := nnw + lnw + pnw + dtw
+	columnW := nnw + lnw + pnw + mkw + dtw + rfw
+	for _, f := range e.footer {
+		columnW = max(columnW, (width(f.nm)+2)*w)
+	}
 	rw := columnW + m*2
 	rh := (len(e.pkeys) + len(e.field)) * h
+	fh := len(e.footer) * h
 	ew := (width(e.title) + 2) * w
 	if ew > columnW {
 		rw = ew + m*2
@@ -128,21 +349,27 @@ func (e *Entity) Build() {
 		m + nnw,
 		m + nnw + lnw,
 		m + nnw + lnw + pnw,
+		m + nnw + lnw + pnw + mkw,
+		m + nnw + lnw + pnw + mkw + dtw,
 	}
 
 	t := h + m/2
 	y := t + len(e.pkeys)*h
 
-	e.view = Rectangle{0, 0, rw + m*2, rh + m*2 + h}
+	e.view = Rectangle{0, 0, rw + m*2, rh + fh + m*2 + h}
 	e.tiltePos = Point{m, h + m - baseLine}
-	e.frame = Rectangle{m, t, rw, rh}
+	e.frame = Rectangle{m, t, rw, rh + fh}
 	e.separateLine = TwoPointCoordinates{m, y, m + rw, y}
+	e.footerLine = TwoPointCoordinates{m, t + rh, m + rw, t + rh}
+	for i, f := range e.footer {
+		f.pt = Point{cellLeft[1], t + rh + (i+1)*h - baseLine}
+	}
 
 	drawRow := func(indexes []int) {
 		for _, i := range indexes {
 			frame := &Rectangle{m, t - h, rw, h}
 			c := e.rows[i]
-			fnm := e.schema + "." + e.name + "." + c.physicalName.nm
+			fnm := e.fullname() + "." + c.physicalName.nm
 			e.collision[fnm] = frame
 			c.frame = frame
 			if c.isNotNull {
@@ -150,7 +377,9 @@ func (e *Entity) Build() {
 			}
 			c.logicalName.pt = Point{cellLeft[1], t - baseLine}
 			c.physicalName.pt = Point{cellLeft[2], t - baseLine}
-			c.dataType.pt = Point{cellLeft[3], t - baseLine}
+			c.marker.pt = Point{cellLeft[3], t - baseLine}
+			c.dataType.pt = Point{cellLeft[4], t - baseLine}
+			c.reference.pt = Point{cellLeft[5], t - baseLine}
 			e.rows[i] = c
 			t += h
 		}
@@ -162,13 +391,13 @@ func (e *Entity) Build() {
 }
 
 func (e *Entity) Draw(s *svg.SVG, dx int, dy int) {
-	s.Group(`id="`+e.name+`"`, e.font)
+	s.Group(`id="`+e.key()+`"`, e.font)
 	s.Text(dx+e.tiltePos.x, dy+e.tiltePos.y, e.title)
 
 	if !e.isChildren {
-		s.Rect(dx+e.frame.x, dy+e.frame.y, e.frame.w, e.frame.h, e.lineStyle)
+		s.Rect(dx+e.frame.x, dy+e.frame.y, e.frame.w, e.frame.h, e.frameStyle)
 	} else {
-		s.Roundrect(dx+e.frame.x, dy+e.frame.y, e.frame.w, e.frame.h, e.radius, e.radius, e.lineStyle)
+		s.Roundrect(dx+e.frame.x, dy+e.frame.y, e.frame.w, e.frame.h, e.radius, e.radius, e.frameStyle)
 	}
 
 	s.Line(dx+e.separateLine.x1, dy+e.separateLine.y1, dx+e.separateLine.x2, dy+e.separateLine.y2, e.lineStyle)
@@ -176,38 +405,78 @@ func (e *Entity) Draw(s *svg.SVG, dx int, dy int) {
 	drawRow := func(indexes []int) {
 		for _, i := range indexes {
 			c := e.rows[i]
+			typeFont := e.typeFont
+			if len(c.style) > 0 {
+				s.Group(c.style)
+				typeFont = c.style
+			}
+			if len(c.tooltip) > 0 {
+				s.Group()
+				s.Title(c.tooltip)
+			}
 			if c.isNotNull {
 				r := c.notNull
 				s.Rect(dx+r.x, dy+r.y, r.w, r.h, e.lineStyle)
 			}
 			s.Text(dx+c.logicalName.pt.x, dy+c.logicalName.pt.y, c.logicalName.nm)
 			s.Text(dx+c.physicalName.pt.x, dy+c.physicalName.pt.y, c.physicalName.nm)
-			s.Text(dx+c.dataType.pt.x, dy+c.dataType.pt.y, c.dataType.nm, e.typeFont)
+			if len(c.marker.nm) > 0 {
+				s.Text(dx+c.marker.pt.x, dy+c.marker.pt.y, c.marker.nm, typeFont)
+			}
+			s.Text(dx+c.dataType.pt.x, dy+c.dataType.pt.y, c.dataType.nm, typeFont)
+			if len(c.reference.nm) > 0 {
+				s.Text(dx+c.reference.pt.x, dy+c.reference.pt.y, c.reference.nm, typeFont)
+			}
+			if len(c.tooltip) > 0 {
+				s.Gend()
+			}
+			if len(c.style) > 0 {
+				s.Gend()
+			}
 		}
 	}
 
 	drawRow(e.pkeys)
 	drawRow(e.field)
+
+	if len(e.footer) > 0 {
+		s.Line(dx+e.footerLine.x1, dy+e.footerLine.y1, dx+e.footerLine.x2, dy+e.footerLine.y2, e.lineStyle)
+		for _, f := range e.footer {
+			s.Text(dx+f.pt.x, dy+f.pt.y, f.nm, e.typeFont)
+		}
+	}
 	s.Gend()
 }
 
-func (e *Entity) getForeignKey() bool {
-	for _, r := range e.rows {
-		if r.relationaly.valid() {
-			return true
-		}
+func (e *Entity) isView() bool {
+	return e.kind == db.KindView || e.kind == db.KindMaterializedView
+}
+
+func (e *Entity) key() string {
+	if e.kind == kindEnum {
+		return kindEnum + ":" + e.fullname()
 	}
-	return false
+	return e.fullname()
+}
+
+func (e *Entity) fullname() string {
+	return e.schema + "." + e.name
+}
+
+func (e *Entity) getForeignKey() bool {
+	return len(e.relations) > 0
 }
 
 func (e *Entity) getColumnWidths() (cw columnWidth) {
-	cw = columnWidth{1, 0, 0, 0}
+	cw = columnWidth{1, 0, 0, 0, 0, 0}
 	f := func(indexes []int) {
 		for _, i := range indexes {
 			c := e.rows[i]
 			cw.logicalName = max(cw.logicalName, width(c.logicalName.nm))
 			cw.physicalName = max(cw.physicalName, width(c.physicalName.nm))
+			cw.marker = max(cw.marker, width(c.marker.nm))
 			cw.dataType = max(cw.dataType, width(c.dataType.nm))
+			cw.reference = max(cw.reference, width(c.reference.nm))
 		}
 	}
 
@@ -220,8 +489,44 @@ func (e *Entity) getColumnWidths() (cw columnWidth) {
 type columnWidth struct {
 	notNull      int
 	physicalName int
+	marker       int
 	dataType     int
 	logicalName  int
+	reference    int
+}
+
+func indexLabel(ix *db.Index) string {
+	label := ix.Name + ": "
+	if ix.IsUnique {
+		label += "UNIQUE "
+	}
+	label += ix.Method + "(" + strings.Join(ix.Columns, ", ") + ")"
+	if len(ix.Predicate) > 0 {
+		label += " WHERE " + ix.Predicate
+	}
+	if len(ix.Comment) > 0 {
+		label += " -- " + ix.Comment
+	}
+	return label
+}
+
+func keyLabel(name string, comment string) string {
+	return name + " -- " + comment
+}
+
+func constraintLabel(con *db.Constraint) string {
+	label := con.Name + ": " + con.Definition
+	if len(con.Comment) > 0 {
+		label += " -- " + con.Comment
+	}
+	return label
+}
+
+func keyMarker(prefix string, k int, j int, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("%s%d", prefix, k)
+	}
+	return fmt.Sprintf("%s%d.%d", prefix, k, j+1)
 }
 
 func width(s string) int {
@@ -0,0 +1,6 @@
+package canvas
+
+const (
+	kindInherits    = "INHERITS"
+	inheritedMarker = "INH"
+)
@@ -0,0 +1,20 @@
+package canvas
+
+const (
+	IndexNone   = ""
+	IndexFooter = "footer"
+	IndexMarker = "marker"
+)
+
+type Options struct {
+	Views   bool
+	Indexes string
+	Enums   bool
+
+	Constraints bool
+
+	ExpandPartitions bool
+	HideInherited    bool
+
+	TypeAbbreviations map[string]string
+}
@@ -18,3 +18,15 @@ type TwoPointCoordinates struct {
 	x2 int
 	y2 int
 }
+
+func (r *Rectangle) union(o *Rectangle) *Rectangle {
+	if r == nil {
+		return o
+	}
+	if o == nil {
+		return r
+	}
+	x := min(r.x, o.x)
+	y := min(r.y, o.y)
+	return &Rectangle{x, y, max(r.x+r.w, o.x+o.w) - x, max(r.y+r.h, o.y+o.h) - y}
+}
@@ -0,0 +1,51 @@
+package canvas
+
+import (
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+const kindEnum = "ENUM"
+
+func NewEnumEntity(en *db.Enum) *Entity {
+	e := NewEntity(en.Schema, en.Name, kindEnum, en.Comment)
+	for i, label := range en.Labels {
+		e.rows = append(e.rows, &row{
+			order:        i,
+			physicalName: column{nm: label},
+		})
+	}
+	e.Build()
+
+	return e
+}
+
+func enumLabel(en *db.Enum) string {
+	labels := []string{}
+	for _, label := range en.Labels {
+		labels = append(labels, "'"+label+"'")
+	}
+	label := "enum " + en.Name + " (" + strings.Join(labels, ", ") + ")"
+	if len(en.Comment) > 0 {
+		label += "\n" + en.Comment
+	}
+	return label
+}
+
+func domainLabel(d *db.Domain) string {
+	label := "domain " + d.Name + " AS " + d.BaseType
+	if d.NotNull {
+		label += " NOT NULL"
+	}
+	if len(d.Default) > 0 {
+		label += " DEFAULT " + d.Default
+	}
+	for _, c := range d.Constraints {
+		label += " " + c
+	}
+	if len(d.Comment) > 0 {
+		label += "\n" + d.Comment
+	}
+	return label
+}
@@ -3,17 +3,62 @@ package config
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	CommandRender   = "render"
+	CommandSnapshot = "snapshot"
+	CommandDiff     = "diff"
+	CommandExport   = "export"
+	CommandMigrate  = "migrate"
+)
+
+var commands = []string{CommandRender, CommandSnapshot, CommandDiff, CommandExport, CommandMigrate}
+
+var (
+	indexStyles = []string{"", "footer", "marker"}
+	backends    = []string{"", "information_schema", "pg_catalog"}
+	formats     = map[string][]string{
+		CommandDiff:   {"", "text", "json", "svg"},
+		CommandExport: {"go", "sql"},
+	}
 )
 
 type Config struct {
+	Command string
+	Input   string
+	Output  string
+	Format  string
+	Args    []string
+
 	Host       string
 	User       string
 	Password   string
 	Port       uint16
 	Database   string
 	AcceptPort uint16
+
+	Schemas        []string
+	ExcludeSchemas []string
+
+	Views   bool
+	Indexes string
+	Enums   bool
+
+	Constraints bool
+
+	ExpandPartitions bool
+	HideInherited    bool
+	StatementTimeout string
+	Backend          string
+	AllowDestructive bool
+
+	TypeAbbreviations map[string]string
 }
 
 func GetConfig() (conf Config, err error) {
@@ -24,13 +69,43 @@ func GetConfig() (conf Config, err error) {
 	pwPtr := flag.String("w", "", "db password")
 	dbPtr := flag.String("d", "", "database name")
 	acceptPtr := flag.Uint("a", 20000, "[server mode] accept port")
-	flag.Parse()
+	schemaPtr := flag.String("s", "", "comma-separated schemas to include (default: all non-system schemas)")
+	excludePtr := flag.String("x", "", "comma-separated schemas to exclude")
+	viewsPtr := flag.Bool("views", false, "render views and materialized views")
+	enumsPtr := flag.Bool("enums", false, "render enum types as boxes linked to their columns")
+	constraintsPtr := flag.Bool("constraints", false, "show CHECK and EXCLUDE constraints under each entity")
+	partitionsPtr := flag.Bool("partitions", false, "draw partitions as separate entities instead of folding them into their parent")
+	inheritedPtr := flag.Bool("hide-inherited", false, "hide columns inherited from a parent table instead of marking them")
+	timeoutPtr := flag.String("timeout", "", "statement_timeout for introspection queries (e.g. \"30s\")")
+	backendPtr := flag.String("backend", "", "introspect through \"information_schema\" or \"pg_catalog\" (default: pg_catalog when some tables are hidden from the current role)")
+	inputPtr := flag.String("i", "", "[render, snapshot, export] read the schema from a snapshot, SQLite, DDL or Go source instead of a database")
+	outputPtr := flag.String("o", "", "output filename")
+	formatPtr := flag.String("format", "", "output format: [diff] \"text\" (default), \"json\" or \"svg\"; [export] \"go\" or \"sql\"")
+	destructivePtr := flag.Bool("destructive", false, "[migrate] emit DROP and column type changes instead of commenting them out")
+	indexesPtr := flag.String("indexes", "", "show indexes as \"footer\" or \"marker\"")
+	command := CommandRender
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+	if !slices.Contains(commands, command) {
+		err = fmt.Errorf("unknown command %q (expected one of %s)", command, strings.Join(commands, ", "))
+		return
+	}
+	flag.CommandLine.Parse(args)
 
 	conf, err = readConfig("./" + *confPtr)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 
+	conf.Command = command
+	conf.Input = *inputPtr
+	conf.Output = *outputPtr
+	conf.Format = *formatPtr
+	conf.Args = flag.Args()
+
 	if len(conf.Host) == 0 || *hostPtr != "localhost" {
 		conf.Host = *hostPtr
 	}
@@ -49,10 +124,65 @@ func GetConfig() (conf Config, err error) {
 	if conf.AcceptPort == 0 || *acceptPtr != 20000 {
 		conf.AcceptPort = uint16(*acceptPtr)
 	}
+	if len(*schemaPtr) > 0 {
+		conf.Schemas = splitList(*schemaPtr)
+	}
+	if len(*excludePtr) > 0 {
+		conf.ExcludeSchemas = splitList(*excludePtr)
+	}
+	if *viewsPtr {
+		conf.Views = true
+	}
+	if len(*indexesPtr) > 0 {
+		conf.Indexes = *indexesPtr
+	}
+	if *enumsPtr {
+		conf.Enums = true
+	}
+	if *constraintsPtr {
+		conf.Constraints = true
+	}
+	if *partitionsPtr {
+		conf.ExpandPartitions = true
+	}
+	if *inheritedPtr {
+		conf.HideInherited = true
+	}
+	if len(*timeoutPtr) > 0 {
+		conf.StatementTimeout = *timeoutPtr
+	}
+	if len(*backendPtr) > 0 {
+		conf.Backend = *backendPtr
+	}
+	if *destructivePtr {
+		conf.AllowDestructive = true
+	}
 
+	if err = checkValue("indexes", conf.Indexes, indexStyles); err != nil {
+		return
+	}
+	if allowed, ok := formats[command]; ok {
+		if err = checkValue(command+" format", conf.Format, allowed); err != nil {
+			return
+		}
+	}
+	err = checkValue("backend", conf.Backend, backends)
 	return
 }
 
+func checkValue(name string, value string, allowed []string) (err error) {
+	if slices.Contains(allowed, value) {
+		return
+	}
+	var quoted []string
+	for _, v := range allowed {
+		if len(v) > 0 {
+			quoted = append(quoted, fmt.Sprintf("%q", v))
+		}
+	}
+	return fmt.Errorf("unknown %s %q (expected one of %s)", name, value, strings.Join(quoted, ", "))
+}
+
 func readConfig(fn string) (conf Config, err error) {
 	conf = Config{}
 
@@ -71,3 +201,13 @@ func readConfig(fn string) (conf Config, err error) {
 
 	return
 }
+
+func splitList(s string) (list []string) {
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return
+}
@@ -0,0 +1,154 @@
+package db
+
+const (
+	BackendAuto              = ""
+	BackendInformationSchema = "information_schema"
+	BackendCatalog           = "pg_catalog"
+)
+
+var relkinds = map[string]string{
+	"r": KindTable,
+	"p": KindTable,
+	"v": KindView,
+	"m": KindMaterializedView,
+	"f": KindForeignTable,
+}
+
+func (c *DBConnect) resolveBackend(schemas []string) (backend string, err error) {
+	if len(c.backend) > 0 {
+		return c.backend, nil
+	}
+
+	backend = c.Backend
+	if backend == BackendAuto {
+		sql := `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				pg_class C
+				INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+			WHERE
+				C.relkind IN ('r', 'p', 'v', 'm', 'f')
+				AND N.nspname IN ?
+				AND NOT pg_has_role(C.relowner, 'USAGE')
+				AND NOT has_table_privilege(C.oid, 'SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER')
+		)
+		`
+		var hidden bool
+		err = c.db.Raw(sql, schemas).Row().Scan(&hidden)
+		if err != nil {
+			return
+		}
+		backend = BackendInformationSchema
+		if hidden {
+			backend = BackendCatalog
+		}
+	}
+	c.backend = backend
+
+	return
+}
+
+func (c *DBConnect) catalogTablenames(schemas []string) (names []TableName, err error) {
+	sql := `
+	SELECT
+		N.nspname,
+		C.relname,
+		C.relkind,
+		C.relispartition
+	FROM
+		pg_class C
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+	WHERE
+		C.relkind IN ('r', 'p', 'v', 'm', 'f')
+		AND N.nspname IN ?
+	ORDER BY
+		1, 2
+	`
+	rows, err := c.db.Raw(sql, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var tn TableName
+		var relkind string
+		rows.Scan(&tn.Schema, &tn.Name, &relkind, &tn.IsPartition)
+		tn.Kind = relkinds[relkind]
+		names = append(names, tn)
+	}
+
+	return
+}
+
+func (c *DBConnect) catalogColumnInfo(schemas []string, relkinds []string, tables tableSet) (err error) {
+	sql := `
+	SELECT
+		N.nspname table_schema,
+		C.relname table_name,
+		A.attname column_name,
+		A.attnum ordinal_position,
+		CASE WHEN A.attgenerated = '' THEN pg_get_expr(AD.adbin, AD.adrelid) END column_default,
+		CASE WHEN A.attnotnull THEN 'NO' ELSE 'YES' END is_nullable,
+		CASE
+			WHEN U.typelem <> 0 AND U.typlen = -1 THEN 'ARRAY'
+			WHEN UN.nspname = 'pg_catalog' THEN format_type(U.oid, NULL)
+			ELSE 'USER-DEFINED'
+		END data_type,
+		information_schema._pg_char_max_length(TM.typid, TM.typmod) character_maximum_length,
+		information_schema._pg_numeric_precision(TM.typid, TM.typmod) numeric_precision,
+		information_schema._pg_numeric_scale(TM.typid, TM.typmod) numeric_scale,
+		information_schema._pg_datetime_precision(TM.typid, TM.typmod) datetime_precision,
+		information_schema._pg_interval_type(TM.typid, TM.typmod) interval_type,
+		UN.nspname udt_schema,
+		U.typname udt_name,
+		CASE WHEN T.typtype = 'd' THEN TN.nspname END domain_schema,
+		CASE WHEN T.typtype = 'd' THEN T.typname END domain_name,
+		CASE WHEN A.attidentity <> '' THEN 'YES' ELSE 'NO' END is_identity,
+		CASE A.attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' END identity_generation,
+		CASE WHEN A.attgenerated <> '' THEN 'ALWAYS' ELSE 'NEVER' END is_generated,
+		CASE WHEN A.attgenerated <> '' THEN pg_get_expr(AD.adbin, AD.adrelid) END generation_expression
+	FROM
+		pg_attribute A
+		INNER JOIN pg_class C ON C.oid = A.attrelid
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		INNER JOIN pg_type T ON T.oid = A.atttypid
+		INNER JOIN pg_namespace TN ON TN.oid = T.typnamespace
+		INNER JOIN pg_type U ON U.oid = CASE WHEN T.typtype = 'd' THEN T.typbasetype ELSE T.oid END
+		INNER JOIN pg_namespace UN ON UN.oid = U.typnamespace
+		LEFT JOIN pg_attrdef AD ON AD.adrelid = A.attrelid AND AD.adnum = A.attnum
+		CROSS JOIN LATERAL (
+			SELECT
+				CASE WHEN U.typelem <> 0 AND U.typlen = -1 THEN U.typelem ELSE U.oid END typid,
+				CASE WHEN T.typtype = 'd' THEN T.typtypmod ELSE A.atttypmod END typmod
+		) TM
+	WHERE
+		C.relkind IN ?
+		AND N.nspname IN ?
+		AND A.attnum > 0
+		AND NOT A.attisdropped
+	ORDER BY
+		N.nspname,
+		C.relname,
+		A.attnum
+	`
+	rows, err := c.db.Raw(sql, relkinds, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var column Column
+		c.db.ScanRows(rows, &column)
+		if info, ok := tables[tableKey(column.TableSchema, column.TableName)]; ok {
+			info.Columns[column.ColumnName] = &column
+		}
+	}
+
+	return
+}
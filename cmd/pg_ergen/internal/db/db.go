@@ -1,6 +1,8 @@
 package db
 
 import (
+	"encoding/json"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -17,7 +19,15 @@ type DBConnect struct {
 	Sslmode  bool
 	TimeZone string
 
-	db *gorm.DB
+	Schemas          []string
+	ExcludeSchemas   []string
+	ExpandPartitions bool
+	StatementTimeout string
+	Backend          string
+
+	db      *gorm.DB
+	types   *UserTypes
+	backend string
 }
 
 func (c *DBConnect) Connect() (self *DBConnect, err error) {
@@ -55,6 +65,8 @@ func (c *DBConnect) Connect() (self *DBConnect, err error) {
 	dsn := strings.Join(params, " ")
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	c.db = db
+	c.types = nil
+	c.backend = ""
 	self = c
 
 	return
@@ -78,8 +90,25 @@ func (c *DBConnect) Databasenames() (names []string, err error) {
 	return
 }
 
-func (c *DBConnect) Tablenames() (names []string, err error) {
-	rows, err := c.db.Table("information_schema.tables").Where("table_schema = ?", "public").Select("table_name").Rows()
+var systemSchemas = []string{"pg_catalog", "information_schema", "pg_toast"}
+
+func selectSchema(schemas []string, exclude []string, name string) bool {
+	if len(schemas) > 0 && !slices.Contains(schemas, name) {
+		return false
+	}
+	return !slices.Contains(exclude, name)
+}
+
+func (c *DBConnect) selectSchema(name string) bool {
+	return selectSchema(c.Schemas, c.ExcludeSchemas, name)
+}
+
+func (c *DBConnect) Schemanames() (names []string, err error) {
+	rows, err := c.db.Table("pg_namespace").
+		Where("nspname NOT IN ? AND nspname NOT LIKE ? AND nspname NOT LIKE ?", systemSchemas, "pg_temp_%", "pg_toast_temp_%").
+		Order("nspname").
+		Select("nspname").
+		Rows()
 	if err != nil {
 		return
 	}
@@ -88,22 +117,126 @@ func (c *DBConnect) Tablenames() (names []string, err error) {
 
 	names = []string{}
 	for rows.Next() {
-		var tableName string
-		rows.Scan(&tableName)
-		names = append(names, tableName)
+		var name string
+		rows.Scan(&name)
+		if c.selectSchema(name) {
+			names = append(names, name)
+		}
+	}
+
+	return
+}
+
+const (
+	KindTable            = "BASE TABLE"
+	KindView             = "VIEW"
+	KindMaterializedView = "MATERIALIZED VIEW"
+	KindForeignTable     = "FOREIGN"
+)
+
+type TableName struct {
+	Schema      string
+	Name        string
+	Kind        string
+	IsPartition bool
+}
+
+func (tn *TableName) IsView() bool {
+	return tn.Kind == KindView || tn.Kind == KindMaterializedView
+}
+
+func (c *DBConnect) Tablenames() (names []TableName, err error) {
+	schemas, err := c.Schemanames()
+	if err != nil {
+		return
+	}
+
+	names = []TableName{}
+	if len(schemas) == 0 {
+		return
+	}
+
+	backend, err := c.resolveBackend(schemas)
+	if err != nil {
+		return
+	}
+
+	var tableNames []TableName
+	if backend == BackendCatalog {
+		tableNames, err = c.catalogTablenames(schemas)
+	} else {
+		tableNames, err = c.schemaTablenames(schemas)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, tn := range tableNames {
+		if tn.IsPartition && !c.ExpandPartitions {
+			continue
+		}
+		names = append(names, tn)
+	}
+
+	return
+}
+
+func (c *DBConnect) schemaTablenames(schemas []string) (names []TableName, err error) {
+	sql := `
+	SELECT
+		T.table_schema,
+		T.table_name,
+		T.table_type,
+		COALESCE(C.relispartition, false)
+	FROM
+		(
+			SELECT table_schema, table_name, table_type FROM information_schema.tables WHERE table_schema IN ?
+			UNION ALL
+			SELECT schemaname, matviewname, ? FROM pg_matviews WHERE schemaname IN ?
+		) T
+		LEFT JOIN pg_namespace N ON N.nspname = T.table_schema
+		LEFT JOIN pg_class C ON C.relnamespace = N.oid AND C.relname = T.table_name
+	ORDER BY
+		1, 2
+	`
+	rows, err := c.db.Raw(sql, schemas, KindMaterializedView, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var tn TableName
+		rows.Scan(&tn.Schema, &tn.Name, &tn.Kind, &tn.IsPartition)
+		names = append(names, tn)
 	}
 
 	return
 }
 
 type ForeignKey struct {
-	ConstraintName string
-	TableSchema    string
-	TableName      string
-	ColumnName     string
-	MatchOption    string
-	UpdateRule     string
-	DeleteRule     string
+	ConstraintName    string
+	Columns           []string
+	TableSchema       string
+	TableName         string
+	ReferencedColumns []string
+	MatchOption       string
+	UpdateRule        string
+	DeleteRule        string
+	IsDeferrable      bool
+	InitiallyDeferred bool
+	Comment           string
+}
+
+type Constraint struct {
+	Name              string
+	Type              string
+	Columns           []string
+	Definition        string
+	IsDeferrable      bool
+	InitiallyDeferred bool
+	Comment           string
 }
 
 type Column struct {
@@ -154,91 +287,69 @@ type Column struct {
 
 	IsPrimaryKey    bool
 	IsUnique        bool
+	IsInherited     bool
 	Comment         string
 	AlternativeName string
-	ForeignKey
+	ForeignKeys     []*ForeignKey `json:"-" gorm:"-"`
+	Enum            *Enum         `json:"-" gorm:"-"`
+	Domain          *Domain       `json:"-" gorm:"-"`
 }
 type Columns map[string]*Column
-type OrdinalColumns map[int]*Column
 
-func (c *DBConnect) columnInfo(n string) (columns Columns, index_for_columns OrdinalColumns, err error) {
-	rows, err := c.db.Table("information_schema.columns").Where("table_name = ?", n).Order("table_name, ordinal_position").Select("*").Rows()
+type tableSet map[string]*TableInfo
+
+func tableKey(schema string, n string) string {
+	return schema + "." + n
+}
+
+func (c *DBConnect) columnInfo(schemas []string, tables tableSet) (err error) {
+	rows, err := c.db.Table("information_schema.columns").Where("table_schema IN ?", schemas).Order("table_schema, table_name, ordinal_position").Select("*").Rows()
 	if err != nil {
 		return
 	}
 
 	defer rows.Close()
 
-	columns = Columns{}
-	index_for_columns = OrdinalColumns{}
 	for rows.Next() {
 		var column Column
 		c.db.ScanRows(rows, &column)
-		columns[column.ColumnName] = &column
-		index_for_columns[column.OrdinalPosition] = &column
+		if info, ok := tables[tableKey(column.TableSchema, column.TableName)]; ok {
+			info.Columns[column.ColumnName] = &column
+		}
 	}
 
-	return
+	return c.arrayTypmods(schemas, tables)
 }
 
-type constraint struct {
-	ConstraintName    string
-	ConstraintCatalog string
-	ConstraintSchema  string
-	ConstraintType    string
-	IsDeferrable      string
-	InitiallyDeferred string
-	Enforced          string
-	TableSchema       string
-	TableName         string
-	ColumnName        string
-	TargetTableSchema string
-	TargetTableName   string
-	TargetColumnName  string
-}
-
-func (c *DBConnect) constraint(n string, col *Columns) (err error) {
+func (c *DBConnect) arrayTypmods(schemas []string, tables tableSet) (err error) {
 	sql := `
 	SELECT
-		A.constraint_name,
-		A.constraint_catalog,
-		A.constraint_schema,
-		A.constraint_type,
-		A.is_deferrable,
-		A.initially_deferred,
-		A.enforced,
-		B.table_schema,
-		B.table_name,
-		B.column_name,
-		C.table_schema target_table_schema,
-		C.table_name target_table_name,
-		C.column_name target_column_name
+		N.nspname table_schema,
+		C.relname table_name,
+		A.attname column_name,
+		information_schema._pg_char_max_length(U.typelem, TM.typmod) character_maximum_length,
+		information_schema._pg_numeric_precision(U.typelem, TM.typmod) numeric_precision,
+		information_schema._pg_numeric_scale(U.typelem, TM.typmod) numeric_scale,
+		information_schema._pg_datetime_precision(U.typelem, TM.typmod) datetime_precision,
+		information_schema._pg_interval_type(U.typelem, TM.typmod) interval_type
 	FROM
-		(
-			SELECT * FROM information_schema.table_constraints
-			WHERE
-				constraint_type <> 'CHECK'
-				AND constraint_schema = ?
-				AND table_name = ?
-		) A
-		INNER JOIN (
-			SELECT * FROM information_schema.key_column_usage ) B
-			USING (
-				constraint_name,
-				constraint_catalog,
-				constraint_schema,
-				table_catalog
-			)
-		INNER JOIN (
-			SELECT * FROM information_schema.constraint_column_usage ) C
-			USING (
-				constraint_name,
-				constraint_catalog,
-				constraint_schema,
-				table_catalog
-			)
+		pg_attribute A
+		INNER JOIN pg_class C ON C.oid = A.attrelid
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		INNER JOIN pg_type T ON T.oid = A.atttypid
+		INNER JOIN pg_type U ON U.oid = CASE WHEN T.typtype = 'd' THEN T.typbasetype ELSE T.oid END
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN T.typtype = 'd' THEN T.typtypmod ELSE A.atttypmod END typmod
+		) TM
+	WHERE
+		N.nspname IN ?
+		AND A.attnum > 0
+		AND NOT A.attisdropped
+		AND U.typelem <> 0
+		AND U.typlen = -1
+		AND TM.typmod >= 0
 	`
-	rows, err := c.db.Raw(sql, "public", n).Rows()
+	rows, err := c.db.Raw(sql, schemas).Rows()
 	if err != nil {
 		return
 	}
@@ -246,39 +357,109 @@ func (c *DBConnect) constraint(n string, col *Columns) (err error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var constraint constraint
-		c.db.ScanRows(rows, &constraint)
-		if constraint.ConstraintType == "PRIMARY KEY" {
-			(*col)[constraint.ColumnName].IsPrimaryKey = true
-		} else if constraint.ConstraintType == "FOREIGN KEY" {
-			(*col)[constraint.ColumnName].ForeignKey = ForeignKey{
-				ConstraintName: constraint.ConstraintName,
-				TableSchema:    constraint.TargetTableSchema,
-				TableName:      constraint.TargetTableName,
-				ColumnName:     constraint.TargetColumnName,
-			}
-		} else if constraint.ConstraintType == "UNIQUE" {
-			(*col)[constraint.ColumnName].IsUnique = true
+		var typmod Column
+		c.db.ScanRows(rows, &typmod)
+		info, ok := tables[tableKey(typmod.TableSchema, typmod.TableName)]
+		if !ok {
+			continue
+		}
+		if column, ok := info.Columns[typmod.ColumnName]; ok {
+			column.CharacterMaximumLength = typmod.CharacterMaximumLength
+			column.NumericPrecision = typmod.NumericPrecision
+			column.NumericScale = typmod.NumericScale
+			column.DatetimePrecision = typmod.DatetimePrecision
+			column.IntervalType = typmod.IntervalType
 		}
 	}
 
 	return
 }
 
-type referentialConstraint struct {
-	ConstraintName          string
-	ConstraintCatalog       string
-	ConstraintSchema        string
-	UniqueConstraintCatalog string
-	UniqueConstraintSchema  string
-	UniqueConstraintName    string
-	MatchOption             string
-	UpdateRule              string
-	DeleteRule              string
+type constraint struct {
+	TableSchema       string
+	TableName         string
+	ConstraintName    string
+	ConstraintType    string
+	ColumnNames       string
+	TargetTableSchema string
+	TargetTableName   string
+	TargetColumnNames string
+	MatchType         string
+	UpdateType        string
+	DeleteType        string
+	IsDeferrable      bool
+	InitiallyDeferred bool
+	Definition        string
+	Comment           string
+}
+
+var constraintTypes = map[string]string{
+	"c": "CHECK",
+	"x": "EXCLUDE",
+}
+
+var matchTypes = map[string]string{
+	"f": "FULL",
+	"p": "PARTIAL",
+	"s": "NONE",
+}
+
+var actionTypes = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
 }
 
-func (c *DBConnect) referentialConstraints(col *Columns) (err error) {
-	rows, err := c.db.Table("information_schema.referential_constraints").Where("constraint_schema = ?", "public").Select("*").Rows()
+func decodeNames(s string) (names []string) {
+	if len(s) > 0 {
+		json.Unmarshal([]byte(s), &names)
+	}
+	return
+}
+
+func (c *DBConnect) constraint(schema []string, tables tableSet) (err error) {
+	sql := `
+	SELECT
+		N.nspname table_schema,
+		B.relname table_name,
+		A.conname constraint_name,
+		A.contype constraint_type,
+		(
+			SELECT array_to_json(array_agg(attname ORDER BY K.n))::text
+			FROM unnest(A.conkey) WITH ORDINALITY K(attnum, n)
+				INNER JOIN pg_attribute ON attrelid = A.conrelid AND attnum = K.attnum
+		) column_names,
+		D.nspname target_table_schema,
+		C.relname target_table_name,
+		(
+			SELECT array_to_json(array_agg(attname ORDER BY K.n))::text
+			FROM unnest(A.confkey) WITH ORDINALITY K(attnum, n)
+				INNER JOIN pg_attribute ON attrelid = A.confrelid AND attnum = K.attnum
+		) target_column_names,
+		A.confmatchtype match_type,
+		A.confupdtype update_type,
+		A.confdeltype delete_type,
+		A.condeferrable is_deferrable,
+		A.condeferred initially_deferred,
+		pg_get_constraintdef(A.oid, true) definition,
+		COALESCE(obj_description(A.oid, 'pg_constraint'), '') comment
+	FROM
+		pg_constraint A
+		INNER JOIN pg_class B ON B.oid = A.conrelid
+		INNER JOIN pg_namespace N ON N.oid = B.relnamespace
+		LEFT JOIN pg_class C ON C.oid = A.confrelid
+		LEFT JOIN pg_namespace D ON D.oid = C.relnamespace
+	WHERE
+		A.contype IN ('p', 'f', 'u', 'c', 'x')
+		AND N.nspname IN ?
+	ORDER BY
+		N.nspname,
+		B.relname,
+		A.conname
+	`
+	rows, err := c.db.Raw(sql, schema).Rows()
 	if err != nil {
 		return
 	}
@@ -286,37 +467,86 @@ func (c *DBConnect) referentialConstraints(col *Columns) (err error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var rc referentialConstraint
-		c.db.ScanRows(rows, &rc)
-		for _, c := range *col {
-			if rc.ConstraintName == c.ForeignKey.ConstraintName {
-				fk := c.ForeignKey
-				fk.MatchOption = rc.MatchOption
-				fk.UpdateRule = rc.UpdateRule
-				fk.DeleteRule = rc.DeleteRule
-				c.ForeignKey = fk
-			}
+		var constraint constraint
+		c.db.ScanRows(rows, &constraint)
+		if info, ok := tables[tableKey(constraint.TableSchema, constraint.TableName)]; ok {
+			info.addConstraint(&constraint)
 		}
 	}
 
 	return
 }
 
-func (c *DBConnect) comment(n string, ifc *OrdinalColumns) (table_comment string, err error) {
+func (info *TableInfo) addConstraint(constraint *constraint) {
+	columnNames := decodeNames(constraint.ColumnNames)
+	switch constraint.ConstraintType {
+	case "p":
+		info.PrimaryKey = columnNames
+		info.PrimaryKeyName = constraint.ConstraintName
+		info.PrimaryKeyComment = constraint.Comment
+		for _, name := range columnNames {
+			if column, ok := info.Columns[name]; ok {
+				column.IsPrimaryKey = true
+			}
+		}
+	case "f":
+		fk := &ForeignKey{
+			ConstraintName:    constraint.ConstraintName,
+			Columns:           columnNames,
+			TableSchema:       constraint.TargetTableSchema,
+			TableName:         constraint.TargetTableName,
+			ReferencedColumns: decodeNames(constraint.TargetColumnNames),
+			MatchOption:       matchTypes[constraint.MatchType],
+			UpdateRule:        actionTypes[constraint.UpdateType],
+			DeleteRule:        actionTypes[constraint.DeleteType],
+			IsDeferrable:      constraint.IsDeferrable,
+			InitiallyDeferred: constraint.InitiallyDeferred,
+			Comment:           constraint.Comment,
+		}
+		info.ForeignKeys = append(info.ForeignKeys, fk)
+		for _, name := range columnNames {
+			if column, ok := info.Columns[name]; ok {
+				column.ForeignKeys = append(column.ForeignKeys, fk)
+			}
+		}
+	case "u":
+		info.addUniqueKey(&UniqueKey{
+			Name:    constraint.ConstraintName,
+			Columns: columnNames,
+			Comment: constraint.Comment,
+		})
+	case "c", "x":
+		info.Constraints = append(info.Constraints, &Constraint{
+			Name:              constraint.ConstraintName,
+			Type:              constraintTypes[constraint.ConstraintType],
+			Columns:           columnNames,
+			Definition:        constraint.Definition,
+			IsDeferrable:      constraint.IsDeferrable,
+			InitiallyDeferred: constraint.InitiallyDeferred,
+			Comment:           constraint.Comment,
+		})
+	}
+}
+
+func (c *DBConnect) comment(schemas []string, tables tableSet) (err error) {
 	sql := `
 	SELECT
-	    objsubid,
-	    description
+		N.nspname,
+		C.relname,
+		COALESCE(A.attname, ''),
+		B.description
 	FROM
-	    pg_stat_user_tables A
-	    INNER JOIN pg_description B
-	    ON A.relid = B.objoid
+		pg_description B
+		INNER JOIN pg_class C ON C.oid = B.objoid AND B.classoid = 'pg_class'::regclass
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		LEFT JOIN pg_attribute A ON A.attrelid = C.oid AND A.attnum = B.objsubid AND B.objsubid > 0
 	WHERE
-	    relname = ?
+		C.relkind IN ('r', 'p', 'v', 'm', 'f')
+		AND N.nspname IN ?
 	ORDER BY
-	    B.objsubid
+		1, 2, B.objsubid
 	`
-	rows, err := c.db.Raw(sql, n).Rows()
+	rows, err := c.db.Raw(sql, schemas).Rows()
 	if err != nil {
 		return
 	}
@@ -324,15 +554,16 @@ func (c *DBConnect) comment(n string, ifc *OrdinalColumns) (table_comment string
 	defer rows.Close()
 
 	for rows.Next() {
-		var objsubid int
-		var description string
-		rows.Scan(&objsubid, &description)
-		if objsubid == 0 {
-			table_comment = description
-		} else {
-			column := (*ifc)[objsubid]
+		var schema, n, name, description string
+		rows.Scan(&schema, &n, &name, &description)
+		info, ok := tables[tableKey(schema, n)]
+		if !ok {
+			continue
+		}
+		if len(name) == 0 {
+			info.Comment = description
+		} else if column, ok := info.Columns[name]; ok {
 			column.Comment = description
-			(*ifc)[objsubid] = column
 		}
 	}
 
@@ -340,39 +571,121 @@ func (c *DBConnect) comment(n string, ifc *OrdinalColumns) (table_comment string
 }
 
 type TableInfo struct {
-	Schema          string
-	Name            string
-	Columns         Columns
-	Comment         string
-	AlternativeName string
+	Schema            string
+	Name              string
+	Kind              string
+	Columns           Columns
+	PrimaryKey        []string
+	PrimaryKeyName    string
+	PrimaryKeyComment string
+	ForeignKeys       []*ForeignKey
+	UniqueKeys        []*UniqueKey
+	Indexes           []*Index
+	Constraints       []*Constraint
+	Dependencies      []TableName
+	PartitionKey      string
+	Partitions        []TableName
+	PartitionOf       *TableName
+	PartitionBound    string
+	Inherits          []TableName
+	Comment           string
+	AlternativeName   string
+}
+
+func (c *DBConnect) GetTableInfo(tn TableName) (info TableInfo, err error) {
+	infos, err := c.GetTableInfos([]TableName{tn})
+	if err != nil {
+		return
+	}
+	info = infos[0]
+
+	return
 }
 
-func (c *DBConnect) GetTableInfo(n string) (info TableInfo, err error) {
-	columns, index_for_columns, err := c.columnInfo(n)
+func (c *DBConnect) GetTableInfos(names []TableName) (infos []TableInfo, err error) {
+	if len(names) == 0 {
+		return
+	}
+
+	infos = make([]TableInfo, len(names))
+	tables := tableSet{}
+	views := tableSet{}
+	schemas := []string{}
+	for i, tn := range names {
+		infos[i] = TableInfo{
+			Schema:  tn.Schema,
+			Name:    tn.Name,
+			Kind:    tn.Kind,
+			Columns: Columns{},
+		}
+		tables[tableKey(tn.Schema, tn.Name)] = &infos[i]
+		if tn.IsView() {
+			views[tableKey(tn.Schema, tn.Name)] = &infos[i]
+		}
+		if !slices.Contains(schemas, tn.Schema) {
+			schemas = append(schemas, tn.Schema)
+		}
+	}
+
+	backend, err := c.resolveBackend(schemas)
 	if err != nil {
 		return
 	}
 
-	err = c.constraint(n, &columns)
+	if backend == BackendCatalog {
+		err = c.catalogColumnInfo(schemas, []string{"r", "p", "v", "m", "f"}, tables)
+	} else {
+		err = c.columnInfo(schemas, tables)
+		if err == nil {
+			err = c.catalogColumnInfo(schemas, []string{"m"}, tables)
+		}
+	}
 	if err != nil {
 		return
 	}
 
-	err = c.referentialConstraints(&columns)
+	types, err := c.UserTypes()
 	if err != nil {
 		return
 	}
+	for _, info := range tables {
+		types.resolve(info.Columns)
+	}
 
-	table_comment, err := c.comment(n, &index_for_columns)
+	err = c.constraint(schemas, tables)
 	if err != nil {
 		return
 	}
 
-	info.Schema = "public"
-	info.Name = n
-	info.Columns = columns
-	info.Comment = table_comment
-	info.AlternativeName = ""
+	err = c.indexes(schemas, tables)
+	if err != nil {
+		return
+	}
+	for _, info := range tables {
+		info.addUniqueIndexes()
+	}
+
+	err = c.comment(schemas, tables)
+	if err != nil {
+		return
+	}
+
+	err = c.partitions(schemas, tables)
+	if err != nil {
+		return
+	}
+
+	err = c.inherits(schemas, tables)
+	if err != nil {
+		return
+	}
+
+	if len(views) > 0 {
+		err = c.dependencies(schemas, views)
+		if err != nil {
+			return
+		}
+	}
 
 	return
 }
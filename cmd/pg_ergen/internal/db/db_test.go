@@ -0,0 +1,250 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func testConnect(tb testing.TB) (c *DBConnect) {
+	tb.Helper()
+	if len(os.Getenv("PGHOST")) == 0 {
+		tb.Skip("PGHOST is not set")
+	}
+
+	c = &DBConnect{
+		Host:     os.Getenv("PGHOST"),
+		User:     os.Getenv("PGUSER"),
+		Password: os.Getenv("PGPASSWORD"),
+		Dbname:   os.Getenv("PGDATABASE"),
+	}
+	if port, err := strconv.Atoi(os.Getenv("PGPORT")); err == nil {
+		c.Port = uint16(port)
+	}
+	if _, err := c.Connect(); err != nil {
+		tb.Fatal(err)
+	}
+	return
+}
+
+func testTablenames(b *testing.B, c *DBConnect) (names []TableName) {
+	b.Helper()
+	names, err := c.Tablenames()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(names) == 0 {
+		b.Skip("no tables in " + c.Dbname)
+	}
+	return
+}
+
+func countQueries(tb testing.TB, c *DBConnect) (n *int) {
+	tb.Helper()
+	n = new(int)
+	err := c.db.Callback().Row().After("gorm:row").Register("pg_ergen:count", func(*gorm.DB) { *n += 1 })
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return
+}
+
+func BenchmarkGetTableInfos(b *testing.B) {
+	c := testConnect(b)
+	names := testTablenames(b, c)
+	queries := countQueries(b, c)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetTableInfos(names); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(names)), "tables")
+	b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+}
+
+type mockQuery struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+func mockConnect(t *testing.T, queries []mockQuery) (c *DBConnect, mock sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		if !strings.Contains(actual, expected) {
+			return fmt.Errorf("query does not contain %q", expected)
+		}
+		return nil
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range queries {
+		rows := sqlmock.NewRows(q.columns)
+		for _, row := range q.rows {
+			rows.AddRow(row...)
+		}
+		mock.ExpectQuery(q.match).WillReturnRows(rows)
+	}
+	return &DBConnect{db: gdb, Backend: BackendCatalog}, mock
+}
+
+func TestGetTableInfosBulk(t *testing.T) {
+	catalogColumns := []string{"table_schema", "table_name", "column_name", "ordinal_position", "column_default", "is_nullable", "data_type", "character_maximum_length", "numeric_precision", "numeric_scale", "datetime_precision", "interval_type", "udt_schema", "udt_name", "domain_schema", "domain_name", "is_identity", "identity_generation", "is_generated", "generation_expression"}
+	column := func(table string, name string, position int, dflt any, nullable string, dataType string, length any, udtSchema string, udt string) []driver.Value {
+		return []driver.Value{"app", table, name, position, dflt, nullable, dataType, length, nil, nil, nil, nil, udtSchema, udt, nil, nil, "NO", nil, "NEVER", nil}
+	}
+	constraintColumns := []string{"table_schema", "table_name", "constraint_name", "constraint_type", "column_names", "target_table_schema", "target_table_name", "target_column_names", "match_type", "update_type", "delete_type", "is_deferrable", "initially_deferred", "definition", "comment"}
+	constraint := func(table string, name string, typ string, columns string, target any, targets any, definition string, comment string) []driver.Value {
+		return []driver.Value{"app", table, name, typ, columns, "app", target, targets, "s", "a", "c", false, false, definition, comment}
+	}
+
+	c, mock := mockConnect(t, []mockQuery{
+		{"_pg_char_max_length(TM.typid, TM.typmod)", catalogColumns, [][]driver.Value{
+			column("orders", "id", 1, "nextval('app.orders_id_seq'::regclass)", "NO", "integer", nil, "pg_catalog", "int4"),
+			column("orders", "user_id", 2, nil, "NO", "integer", nil, "pg_catalog", "int4"),
+			column("orders", "state", 3, "'new'::app.state", "NO", "USER-DEFINED", nil, "app", "state"),
+			column("recent", "id", 1, nil, "YES", "integer", nil, "pg_catalog", "int4"),
+			column("users", "id", 1, nil, "NO", "integer", nil, "pg_catalog", "int4"),
+			column("users", "email", 2, nil, "NO", "character varying", "320", "pg_catalog", "varchar"),
+			column("ignored", "id", 1, nil, "NO", "integer", nil, "pg_catalog", "int4"),
+		}},
+		{"pg_enum E", []string{"nspname", "typname", "labels", "comment"}, [][]driver.Value{
+			{"app", "state", `["new","paid"]`, "lifecycle"},
+		}},
+		{"T.typtype = 'd'", []string{"nspname", "typname", "format_type", "typnotnull", "typdefault", "constraints", "comment"}, nil},
+		{"pg_constraint A", constraintColumns, [][]driver.Value{
+			constraint("orders", "orders_pkey", "p", `["id"]`, nil, nil, "PRIMARY KEY (id)", ""),
+			constraint("orders", "orders_user_id_fkey", "f", `["user_id"]`, "users", `["id"]`, "FOREIGN KEY (user_id) REFERENCES app.users(id) ON DELETE CASCADE", "owner"),
+			constraint("users", "users_email_key", "u", `["email"]`, nil, nil, "UNIQUE (email)", ""),
+			constraint("users", "users_email_check", "c", `["email"]`, nil, nil, "CHECK (email ~ '@')", ""),
+			constraint("users", "users_pkey", "p", `["id"]`, nil, nil, "PRIMARY KEY (id)", "surrogate"),
+		}},
+		{"pg_index I", []string{"table_schema", "table_name", "index_name", "column_names", "is_unique", "is_primary", "predicate", "method", "comment"}, [][]driver.Value{
+			{"app", "orders", "orders_pkey", `["id"]`, true, true, nil, "btree", ""},
+			{"app", "orders", "orders_state_idx", `["state"]`, false, false, "state <> 'paid'::app.state", "btree", "hot"},
+			{"app", "users", "users_email_key", `["email"]`, true, false, nil, "btree", ""},
+			{"app", "users", "users_lower_email_idx", `["lower(email::text)"]`, true, false, nil, "btree", ""},
+			{"app", "users", "users_pkey", `["id"]`, true, true, nil, "btree", ""},
+		}},
+		{"pg_description B", []string{"nspname", "relname", "attname", "description"}, [][]driver.Value{
+			{"app", "users", "", "Registered users"},
+			{"app", "users", "email", "login"},
+		}},
+		{"pg_get_partkeydef", []string{"nspname", "relname", "partkey", "bound", "parent_schema", "parent_name"}, nil},
+		{"I.inhseqno", []string{"nspname", "relname", "parent_schema", "parent_name"}, nil},
+		{"A.attinhcount > 0", []string{"nspname", "relname", "attname"}, nil},
+		{"pg_rewrite R", []string{"nspname", "relname", "ref_schema", "ref_name"}, [][]driver.Value{
+			{"app", "recent", "app", "orders"},
+		}},
+	})
+
+	infos, err := c.GetTableInfos([]TableName{
+		{Schema: "app", Name: "users", Kind: KindTable},
+		{Schema: "app", Name: "orders", Kind: KindTable},
+		{Schema: "app", Name: "recent", Kind: KindView},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	users, orders, recent := &infos[0], &infos[1], &infos[2]
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"users columns", len(users.Columns), 2},
+		{"users comment", users.Comment, "Registered users"},
+		{"users.email type", users.Columns["email"].FormatType(nil), "varchar(320)"},
+		{"users.email comment", users.Columns["email"].Comment, "login"},
+		{"users primary key", users.PrimaryKey, []string{"id"}},
+		{"users primary key comment", users.PrimaryKeyComment, "surrogate"},
+		{"users.id primary", users.Columns["id"].IsPrimaryKey, true},
+		{"users unique keys", len(users.UniqueKeys), 2},
+		{"users constraints", len(users.Constraints), 1},
+		{"orders columns", len(orders.Columns), 3},
+		{"orders.state enum", orders.Columns["state"].Enum != nil && orders.Columns["state"].Enum.Name == "state", true},
+		{"orders foreign keys", len(orders.ForeignKeys), 1},
+		{"orders.user_id foreign keys", len(orders.Columns["user_id"].ForeignKeys), 1},
+		{"orders indexes", len(orders.Indexes), 2},
+		{"recent columns", len(recent.Columns), 1},
+	}
+	for _, tt := range tests {
+		if !equalValues(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !slices.Equal(recent.Dependencies, []TableName{{Schema: "app", Name: "orders"}}) {
+		t.Errorf("recent dependencies = %v, want app.orders", recent.Dependencies)
+	}
+
+	fk := orders.ForeignKeys[0]
+	if fk.TableName != "users" || !slices.Equal(fk.ReferencedColumns, []string{"id"}) || fk.DeleteRule != "CASCADE" || fk.Comment != "owner" {
+		t.Errorf("orders_user_id_fkey = %+v", fk)
+	}
+}
+
+func TestArrayTypmods(t *testing.T) {
+	c := testConnect(t)
+	const schema = "pg_ergen_array_test"
+	if err := c.db.Exec(`
+		DROP SCHEMA IF EXISTS ` + schema + ` CASCADE;
+		CREATE SCHEMA ` + schema + `;
+		CREATE DOMAIN ` + schema + `.codes AS varchar(8)[];
+		CREATE TABLE ` + schema + `.t (
+			tags varchar(20)[],
+			amounts numeric(10,2)[],
+			stamps timestamptz(3)[],
+			plain text[],
+			codes ` + schema + `.codes
+		);
+	`).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	want := map[string]string{
+		"tags":    "varchar(20)[]",
+		"amounts": "numeric(10,2)[]",
+		"stamps":  "timestamptz(3)[]",
+		"plain":   "text[]",
+	}
+	for _, backend := range []string{BackendInformationSchema, BackendCatalog} {
+		t.Run(backend, func(t *testing.T) {
+			c.Backend = backend
+			info, err := c.GetTableInfo(TableName{Schema: schema, Name: "t"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, typ := range want {
+				if got := info.Columns[name].FormatType(nil); got != typ {
+					t.Errorf("%s = %q, want %q", name, got, typ)
+				}
+			}
+			if got := info.Columns["codes"].CharacterMaximumLength; got != "8" {
+				t.Errorf("codes length = %q, want 8", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,1134 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type DDLSource struct {
+	Path string
+}
+
+func (s *DDLSource) Snapshot() (model Model, err error) {
+	files := []string{s.Path}
+	stat, err := os.Stat(s.Path)
+	if err != nil {
+		return
+	}
+	if stat.IsDir() {
+		files, err = filepath.Glob(filepath.Join(s.Path, "*.sql"))
+		if err != nil {
+			return
+		}
+		sort.Strings(files)
+	}
+
+	p := newDDLParser()
+	for _, fn := range files {
+		var src []byte
+		src, err = os.ReadFile(fn)
+		if err != nil {
+			return
+		}
+
+		var statements []*statement
+		statements, err = splitStatements(string(src))
+		if err != nil {
+			err = fmt.Errorf("%s: %w", fn, err)
+			return
+		}
+		for _, st := range statements {
+			p.location = fmt.Sprintf("%s:%d", fn, st.line)
+			if e := p.statement(st); e != nil {
+				log.Printf("%s: skipping statement: %v", p.location, e)
+			}
+		}
+	}
+
+	model = p.model()
+	model.Database = strings.TrimSuffix(filepath.Base(s.Path), filepath.Ext(s.Path))
+	model.SnapshotAt = time.Now()
+
+	return
+}
+
+type ddlConstraint struct {
+	table      string
+	constraint constraint
+	columns    []string
+	targets    []string
+}
+
+type ddlIndex struct {
+	table string
+	index *Index
+}
+
+type ddlComment struct {
+	kind  string
+	names []string
+	on    []string
+	text  string
+}
+
+type ddlParser struct {
+	location    string
+	searchPath  string
+	tables      map[string]*TableInfo
+	columnTypes map[*Column]typeSpec
+	domains     map[string]*typeSpec
+	types       *UserTypes
+	constraints []*ddlConstraint
+	indexes     []*ddlIndex
+	comments    []*ddlComment
+}
+
+func newDDLParser() *ddlParser {
+	return &ddlParser{
+		searchPath:  "public",
+		tables:      map[string]*TableInfo{},
+		columnTypes: map[*Column]typeSpec{},
+		domains:     map[string]*typeSpec{},
+		types: &UserTypes{
+			Enums:   map[string]*Enum{},
+			Domains: map[string]*Domain{},
+		},
+	}
+}
+
+func (p *ddlParser) qualify(names []string) (schema string, n string) {
+	switch len(names) {
+	case 0:
+		return
+	case 1:
+		return p.searchPath, names[0]
+	}
+	return names[len(names)-2], names[len(names)-1]
+}
+
+func (p *ddlParser) qualifiedName(c *cursor) (schema string, n string, err error) {
+	names, err := c.names()
+	if err != nil {
+		return
+	}
+	schema, n = p.qualify(names)
+	return
+}
+
+func (p *ddlParser) statement(st *statement) (err error) {
+	c := &cursor{st, 0, len(st.tokens)}
+	switch {
+	case c.accept("create"):
+		c.accept("or", "replace")
+		for c.accept("global") || c.accept("local") || c.accept("temp") || c.accept("temporary") || c.accept("unlogged") {
+		}
+		switch {
+		case c.accept("table"):
+			return p.createTable(c)
+		case c.accept("unique", "index"):
+			return p.createIndex(c, true)
+		case c.accept("index"):
+			return p.createIndex(c, false)
+		case c.accept("type"):
+			return p.createType(c)
+		case c.accept("domain"):
+			return p.createDomain(c)
+		}
+	case c.accept("alter", "table"):
+		return p.alterTable(c)
+	case c.accept("comment", "on"):
+		return p.comment(c)
+	case c.accept("set", "search_path"):
+		if !c.accept("to") {
+			c.acceptPunct("=")
+		}
+		var name string
+		name, err = c.identifier()
+		if err == nil {
+			p.searchPath = name
+		}
+	}
+	return
+}
+
+func (p *ddlParser) createTable(c *cursor) (err error) {
+	c.accept("if", "not", "exists")
+	schema, n, err := p.qualifiedName(c)
+	if err != nil {
+		return
+	}
+
+	info := &TableInfo{
+		Schema:  schema,
+		Name:    n,
+		Kind:    KindTable,
+		Columns: Columns{},
+	}
+	p.tables[tableKey(schema, n)] = info
+
+	if c.accept("partition", "of") {
+		var parent TableName
+		parent.Schema, parent.Name, err = p.qualifiedName(c)
+		if err != nil {
+			return
+		}
+		info.PartitionOf = &parent
+		if t := c.peek(); t != nil && t.punct("(") {
+			var inner *cursor
+			inner, err = c.group()
+			if err != nil {
+				return
+			}
+			for _, part := range inner.split() {
+				if part.is("constraint", "primary", "unique", "foreign", "check", "exclude") {
+					err = p.tableConstraint(info, part)
+					if err != nil {
+						return
+					}
+				}
+			}
+		}
+		info.PartitionBound = c.until("partition")
+	} else {
+		var inner *cursor
+		inner, err = c.group()
+		if err != nil {
+			return
+		}
+		for _, part := range inner.split() {
+			switch {
+			case part.is("like"):
+				log.Printf("%s: skipping %s in table %s.%s: copied columns are not supported", p.location, part.rest(), schema, n)
+			case part.is("constraint", "primary", "unique", "foreign", "check", "exclude"):
+				err = p.tableConstraint(info, part)
+			default:
+				err = p.columnDefinition(info, part)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	for !c.done() {
+		switch {
+		case c.accept("inherits"):
+			var inner *cursor
+			inner, err = c.group()
+			if err != nil {
+				return
+			}
+			for _, part := range inner.split() {
+				var parent TableName
+				parent.Schema, parent.Name, err = p.qualifiedName(part)
+				if err != nil {
+					return
+				}
+				info.Inherits = append(info.Inherits, parent)
+			}
+		case c.accept("partition", "by"):
+			from := c.i
+			c.i += 1
+			if _, err = c.group(); err != nil {
+				return
+			}
+			info.PartitionKey = strings.ToUpper(c.st.tokens[from].text) + " " + c.st.text(from+1, c.i)
+		default:
+			c.i += 1
+		}
+	}
+
+	return
+}
+
+var columnConstraintWords = []string{"constraint", "not", "null", "default", "primary", "unique", "references", "check", "generated", "collate", "deferrable", "initially"}
+
+func (p *ddlParser) columnDefinition(info *TableInfo, c *cursor) (err error) {
+	name, err := c.identifier()
+	if err != nil {
+		return
+	}
+	spec, err := c.typeSpec(columnConstraintWords...)
+	if err != nil {
+		return
+	}
+
+	return p.columnConstraints(info, p.addColumn(info, name, spec), c)
+}
+
+func SerialDefault(schema string, table string, column string) string {
+	sequence := table + "_" + column + "_seq"
+	if schema != "public" {
+		sequence = schema + "." + sequence
+	}
+	return fmt.Sprintf("nextval('%s'::regclass)", sequence)
+}
+
+func (p *ddlParser) addColumn(info *TableInfo, name string, spec typeSpec) (column *Column) {
+	column = &Column{
+		TableSchema:     info.Schema,
+		TableName:       info.Name,
+		ColumnName:      name,
+		OrdinalPosition: len(info.Columns) + 1,
+		IsNullable:      "YES",
+		IsIdentity:      "NO",
+		IsGenerated:     "NEVER",
+	}
+	if spec.isSerial() {
+		column.IsNullable = "NO"
+		column.ColumnDefault = SerialDefault(info.Schema, info.Name, name)
+	}
+	info.Columns[name] = column
+	p.columnTypes[column] = spec
+
+	return
+}
+
+func (p *ddlParser) columnConstraints(info *TableInfo, column *Column, c *cursor) (err error) {
+	var last *ddlConstraint
+	constraintName := ""
+	add := func(contype string, definition string) *ddlConstraint {
+		dc := p.addConstraint(info, constraintName, contype, []string{column.ColumnName}, definition)
+		constraintName = ""
+		return dc
+	}
+
+	for !c.done() {
+		from := c.i
+		switch {
+		case c.accept("constraint"):
+			constraintName, err = c.identifier()
+		case c.accept("not", "null"):
+			column.IsNullable = "NO"
+		case c.accept("null"):
+		case c.accept("default"):
+			column.ColumnDefault = c.until(columnConstraintWords...)
+		case c.accept("primary", "key"):
+			column.IsNullable = "NO"
+			last = add("p", "PRIMARY KEY ("+column.ColumnName+")")
+		case c.accept("unique"):
+			c.accept("nulls", "not", "distinct")
+			c.accept("nulls", "distinct")
+			last = add("u", "UNIQUE ("+column.ColumnName+")")
+		case c.accept("references"):
+			last = add("f", "")
+			err = p.references(last, c)
+			last.constraint.Definition = "FOREIGN KEY (" + column.ColumnName + ") " + c.st.text(from, c.i)
+		case c.accept("check"):
+			var inner *cursor
+			inner, err = c.group()
+			if err == nil {
+				last = add("c", "CHECK ("+inner.rest()+")")
+				if c.accept("no", "inherit") {
+					last.constraint.Definition += " NO INHERIT"
+				}
+			}
+		case c.accept("generated"):
+			kind := "ALWAYS"
+			if c.accept("by", "default") {
+				kind = "BY DEFAULT"
+			} else {
+				c.accept("always")
+			}
+			c.accept("as")
+			if c.accept("identity") {
+				column.IsIdentity = "YES"
+				column.IdentityGeneration = kind
+				column.IsNullable = "NO"
+				if t := c.peek(); t != nil && t.punct("(") {
+					_, err = c.group()
+				}
+			} else {
+				var inner *cursor
+				inner, err = c.group()
+				if err == nil {
+					column.IsGenerated = "ALWAYS"
+					column.GenerationExpression = inner.rest()
+				}
+				c.accept("stored")
+			}
+		case c.accept("collate"):
+			_, err = c.names()
+		case c.accept("not", "deferrable"), c.accept("initially", "immediate"):
+		case c.accept("deferrable"):
+			if last != nil {
+				last.constraint.IsDeferrable = true
+			}
+		case c.accept("initially", "deferred"):
+			if last != nil {
+				last.constraint.InitiallyDeferred = true
+			}
+		default:
+			err = c.errorf("unsupported column constraint")
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *ddlParser) addConstraint(info *TableInfo, name string, contype string, columns []string, definition string) *ddlConstraint {
+	if len(name) == 0 {
+		switch contype {
+		case "p":
+			name = info.Name + "_pkey"
+		case "u":
+			name = info.Name + "_" + strings.Join(columns, "_") + "_key"
+		case "f":
+			name = info.Name + "_" + strings.Join(columns, "_") + "_fkey"
+		case "c":
+			name = info.Name + "_" + strings.Join(columns, "_") + "_check"
+			if len(columns) == 0 {
+				name = info.Name + "_check"
+			}
+		case "x":
+			name = info.Name + "_" + strings.Join(columns, "_") + "_excl"
+		}
+	}
+
+	dc := &ddlConstraint{
+		table:   tableKey(info.Schema, info.Name),
+		columns: columns,
+		constraint: constraint{
+			TableSchema:    info.Schema,
+			TableName:      info.Name,
+			ConstraintName: name,
+			ConstraintType: contype,
+			MatchType:      "s",
+			UpdateType:     "a",
+			DeleteType:     "a",
+			Definition:     definition,
+		},
+	}
+	p.constraints = append(p.constraints, dc)
+
+	return dc
+}
+
+var actionCodes = map[string]string{
+	"NO ACTION":   "a",
+	"RESTRICT":    "r",
+	"CASCADE":     "c",
+	"SET NULL":    "n",
+	"SET DEFAULT": "d",
+}
+
+func (p *ddlParser) references(dc *ddlConstraint, c *cursor) (err error) {
+	dc.constraint.TargetTableSchema, dc.constraint.TargetTableName, err = p.qualifiedName(c)
+	if err != nil {
+		return
+	}
+	if t := c.peek(); t != nil && t.punct("(") {
+		dc.targets, err = c.columnNames()
+		if err != nil {
+			return
+		}
+	}
+
+	for !c.done() {
+		switch {
+		case c.accept("match", "full"):
+			dc.constraint.MatchType = "f"
+		case c.accept("match", "partial"):
+			dc.constraint.MatchType = "p"
+		case c.accept("match", "simple"):
+			dc.constraint.MatchType = "s"
+		case c.accept("on", "delete"):
+			dc.constraint.DeleteType = p.action(c)
+		case c.accept("on", "update"):
+			dc.constraint.UpdateType = p.action(c)
+		case c.accept("not", "deferrable"), c.accept("initially", "immediate"):
+		case c.accept("deferrable"):
+			dc.constraint.IsDeferrable = true
+		case c.accept("initially", "deferred"):
+			dc.constraint.InitiallyDeferred = true
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func (p *ddlParser) action(c *cursor) string {
+	for action, code := range actionCodes {
+		if c.accept(strings.Fields(action)...) {
+			if t := c.peek(); t != nil && t.punct("(") {
+				c.group()
+			}
+			return code
+		}
+	}
+	return "a"
+}
+
+func (p *ddlParser) tableConstraint(info *TableInfo, c *cursor) (err error) {
+	name := ""
+	if c.accept("constraint") {
+		name, err = c.identifier()
+		if err != nil {
+			return
+		}
+	}
+
+	from := c.i
+	var dc *ddlConstraint
+	switch {
+	case c.accept("primary", "key"):
+		var columns []string
+		columns, err = c.columnNames()
+		if err != nil {
+			return
+		}
+		for _, column := range columns {
+			if col, ok := info.Columns[column]; ok {
+				col.IsNullable = "NO"
+			}
+		}
+		dc = p.addConstraint(info, name, "p", columns, "")
+	case c.accept("unique"):
+		c.accept("nulls", "not", "distinct")
+		c.accept("nulls", "distinct")
+		var columns []string
+		columns, err = c.columnNames()
+		if err != nil {
+			return
+		}
+		dc = p.addConstraint(info, name, "u", columns, "")
+	case c.accept("foreign", "key"):
+		var columns []string
+		columns, err = c.columnNames()
+		if err != nil {
+			return
+		}
+		if !c.accept("references") {
+			return c.errorf("REFERENCES expected")
+		}
+		dc = p.addConstraint(info, name, "f", columns, "")
+		err = p.references(dc, c)
+		if err != nil {
+			return
+		}
+	case c.accept("check"):
+		var inner *cursor
+		inner, err = c.group()
+		if err != nil {
+			return
+		}
+		expr := inner.rest()
+		columns := []string{}
+		for name := range info.Columns {
+			if slices.ContainsFunc(strings.FieldsFunc(expr, isNotIdentifier), func(w string) bool { return strings.EqualFold(w, name) }) {
+				columns = append(columns, name)
+			}
+		}
+		sort.Strings(columns)
+		dc = p.addConstraint(info, name, "c", columns, "CHECK ("+expr+")")
+		if c.accept("no", "inherit") {
+			dc.constraint.Definition += " NO INHERIT"
+		}
+	case c.accept("exclude"):
+		if c.accept("using") {
+			c.i += 1
+		}
+		var inner *cursor
+		inner, err = c.group()
+		if err != nil {
+			return
+		}
+		columns := []string{}
+		for _, part := range inner.split() {
+			if column, e := part.identifier(); e == nil && part.is("with") {
+				columns = append(columns, column)
+			}
+		}
+		c.until("deferrable", "initially", "not")
+		dc = p.addConstraint(info, name, "x", columns, "")
+	default:
+		return c.errorf("unsupported table constraint")
+	}
+
+	for !c.done() {
+		switch {
+		case c.accept("not", "deferrable"), c.accept("initially", "immediate"):
+		case c.accept("deferrable"):
+			dc.constraint.IsDeferrable = true
+		case c.accept("initially", "deferred"):
+			dc.constraint.InitiallyDeferred = true
+		default:
+			c.i += 1
+		}
+	}
+	if len(dc.constraint.Definition) == 0 {
+		dc.constraint.Definition = c.st.text(from, c.end)
+	}
+
+	return
+}
+
+func isNotIdentifier(r rune) bool {
+	return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= 0x80)
+}
+
+func (p *ddlParser) table(c *cursor) (info *TableInfo, err error) {
+	schema, n, err := p.qualifiedName(c)
+	if err != nil {
+		return
+	}
+	return p.tables[tableKey(schema, n)], nil
+}
+
+func (p *ddlParser) alterTable(c *cursor) (err error) {
+	c.accept("if", "exists")
+	c.accept("only")
+	info, err := p.table(c)
+	if err != nil || info == nil {
+		return
+	}
+
+	for _, action := range c.split() {
+		switch {
+		case action.accept("add"):
+			if action.is("constraint", "primary", "unique", "foreign", "check", "exclude") {
+				err = p.tableConstraint(info, action)
+			} else {
+				action.accept("column")
+				action.accept("if", "not", "exists")
+				err = p.columnDefinition(info, action)
+			}
+		case action.accept("drop", "constraint"):
+			action.accept("if", "exists")
+			var name string
+			name, err = action.identifier()
+			p.constraints = slices.DeleteFunc(p.constraints, func(dc *ddlConstraint) bool {
+				return dc.table == tableKey(info.Schema, info.Name) && dc.constraint.ConstraintName == name
+			})
+		case action.accept("drop"):
+			action.accept("column")
+			action.accept("if", "exists")
+			var name string
+			name, err = action.identifier()
+			delete(info.Columns, name)
+		case action.accept("alter"):
+			action.accept("column")
+			var name string
+			name, err = action.identifier()
+			if err != nil {
+				return
+			}
+			column, ok := info.Columns[name]
+			if !ok {
+				return fmt.Errorf("unknown column %s.%s", info.Name, name)
+			}
+			err = p.alterColumn(column, action)
+		case action.accept("rename", "column"), action.accept("rename"):
+			if action.accept("to") || action.accept("constraint") {
+				continue
+			}
+			var from, to string
+			from, err = action.identifier()
+			if err == nil && action.accept("to") {
+				to, err = action.identifier()
+			}
+			if err != nil {
+				return
+			}
+			if column, ok := info.Columns[from]; ok {
+				delete(info.Columns, from)
+				column.ColumnName = to
+				info.Columns[to] = column
+			}
+		case action.accept("attach", "partition"):
+			var child *TableInfo
+			child, err = p.table(action)
+			if err != nil || child == nil {
+				return
+			}
+			child.PartitionOf = &TableName{Schema: info.Schema, Name: info.Name}
+			child.PartitionBound = action.rest()
+		case action.accept("inherit"):
+			var parent TableName
+			parent.Schema, parent.Name, err = p.qualifiedName(action)
+			info.Inherits = append(info.Inherits, parent)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *ddlParser) alterColumn(column *Column, c *cursor) (err error) {
+	switch {
+	case c.accept("set", "default"):
+		column.ColumnDefault = c.rest()
+	case c.accept("drop", "default"):
+		column.ColumnDefault = ""
+	case c.accept("set", "not", "null"):
+		column.IsNullable = "NO"
+	case c.accept("drop", "not", "null"):
+		column.IsNullable = "YES"
+	case c.accept("set", "data", "type"), c.accept("type"):
+		var spec typeSpec
+		spec, err = c.typeSpec("collate", "using")
+		if err == nil {
+			p.columnTypes[column] = spec
+		}
+	case c.accept("add", "generated"):
+		column.IsIdentity = "YES"
+		column.IdentityGeneration = "ALWAYS"
+		if c.accept("by", "default") {
+			column.IdentityGeneration = "BY DEFAULT"
+		}
+	}
+	return
+}
+
+func (p *ddlParser) createIndex(c *cursor, unique bool) (err error) {
+	c.accept("concurrently")
+	c.accept("if", "not", "exists")
+	name := ""
+	if !c.is("on") {
+		var names []string
+		names, err = c.names()
+		if err != nil {
+			return
+		}
+		name = names[len(names)-1]
+	}
+	if !c.accept("on") {
+		return c.errorf("ON expected")
+	}
+	c.accept("only")
+	info, err := p.table(c)
+	if err != nil || info == nil {
+		return
+	}
+
+	ix := &Index{IsUnique: unique, Method: "btree"}
+	if c.accept("using") {
+		ix.Method, err = c.identifier()
+		if err != nil {
+			return
+		}
+	}
+	inner, err := c.group()
+	if err != nil {
+		return
+	}
+	for _, part := range inner.split() {
+		t := part.peek()
+		if t != nil && (t.kind == tokenWord || t.kind == tokenIdent) {
+			part.i += 1
+			if part.done() || part.is("asc", "desc", "nulls", "collate") || (part.peek().kind == tokenWord && !part.peek().punct("(")) {
+				ix.Columns = append(ix.Columns, t.name())
+				continue
+			}
+			part.i -= 1
+		}
+		ix.Columns = append(ix.Columns, part.rest())
+	}
+	if len(name) == 0 {
+		parts := []string{info.Name}
+		for _, column := range ix.Columns {
+			if words := strings.FieldsFunc(column, isNotIdentifier); len(words) > 0 {
+				parts = append(parts, words[0])
+			}
+		}
+		name = strings.Join(parts, "_") + "_idx"
+	}
+	ix.Name = name
+
+	c.until("where")
+	if c.accept("where") {
+		ix.Predicate = c.rest()
+	}
+	p.indexes = append(p.indexes, &ddlIndex{tableKey(info.Schema, info.Name), ix})
+
+	return
+}
+
+func (p *ddlParser) createType(c *cursor) (err error) {
+	schema, n, err := p.qualifiedName(c)
+	if err != nil {
+		return
+	}
+	if !c.accept("as", "enum") {
+		return
+	}
+	inner, err := c.group()
+	if err != nil {
+		return
+	}
+
+	enum := &Enum{Schema: schema, Name: n}
+	for _, part := range inner.split() {
+		var label string
+		label, err = part.str()
+		if err != nil {
+			return
+		}
+		enum.Labels = append(enum.Labels, label)
+	}
+	p.types.Enums[tableKey(schema, n)] = enum
+
+	return
+}
+
+func (p *ddlParser) createDomain(c *cursor) (err error) {
+	schema, n, err := p.qualifiedName(c)
+	if err != nil {
+		return
+	}
+	c.accept("as")
+	spec, err := c.typeSpec("collate", "default", "constraint", "not", "null", "check")
+	if err != nil {
+		return
+	}
+
+	domain := &Domain{Schema: schema, Name: n}
+	for !c.done() {
+		switch {
+		case c.accept("default"):
+			domain.Default = c.until("constraint", "not", "null", "check")
+		case c.accept("not", "null"):
+			domain.NotNull = true
+		case c.accept("check"):
+			var inner *cursor
+			inner, err = c.group()
+			if err != nil {
+				return
+			}
+			domain.Constraints = append(domain.Constraints, "CHECK ("+inner.rest()+")")
+		default:
+			c.i += 1
+		}
+	}
+	p.domains[tableKey(schema, n)] = &spec
+	p.types.Domains[tableKey(schema, n)] = domain
+
+	return
+}
+
+func (p *ddlParser) comment(c *cursor) (err error) {
+	dc := &ddlComment{}
+	switch {
+	case c.accept("table"), c.accept("view"), c.accept("materialized", "view"), c.accept("foreign", "table"):
+		dc.kind = "table"
+	case c.accept("column"):
+		dc.kind = "column"
+	case c.accept("constraint"):
+		dc.kind = "constraint"
+	case c.accept("index"):
+		dc.kind = "index"
+	case c.accept("type"), c.accept("domain"):
+		dc.kind = "type"
+	default:
+		return
+	}
+
+	dc.names, err = c.names()
+	if err != nil {
+		return
+	}
+	if dc.kind == "constraint" {
+		if !c.accept("on") {
+			return c.errorf("ON expected")
+		}
+		c.accept("domain")
+		dc.on, err = c.names()
+		if err != nil {
+			return
+		}
+	}
+	if !c.accept("is") {
+		return c.errorf("IS expected")
+	}
+	if !c.accept("null") {
+		dc.text, err = c.str()
+	}
+	p.comments = append(p.comments, dc)
+
+	return
+}
+
+func encodeNames(names []string) string {
+	b, _ := json.Marshal(names)
+	return string(b)
+}
+
+func (p *ddlParser) model() (model Model) {
+	done := map[string]bool{}
+	for key := range p.tables {
+		p.inheritColumns(key, done)
+	}
+	done = map[string]bool{}
+	for key := range p.tables {
+		p.inheritChecks(key, done)
+	}
+
+	for column, spec := range p.columnTypes {
+		spec.apply(column, p)
+	}
+	for key, spec := range p.domains {
+		p.types.Domains[key].BaseType = spec.String()
+	}
+	for _, info := range p.tables {
+		p.types.resolve(info.Columns)
+	}
+
+	sort.SliceStable(p.constraints, func(i, j int) bool {
+		return p.constraints[i].constraint.ConstraintName < p.constraints[j].constraint.ConstraintName
+	})
+	for _, foreign := range []bool{false, true} {
+		for _, dc := range p.constraints {
+			info, ok := p.tables[dc.table]
+			if !ok || (dc.constraint.ConstraintType == "f") != foreign {
+				continue
+			}
+			if foreign && len(dc.targets) == 0 {
+				if target, ok := p.tables[tableKey(dc.constraint.TargetTableSchema, dc.constraint.TargetTableName)]; ok {
+					dc.targets = target.PrimaryKey
+				}
+			}
+			dc.constraint.ColumnNames = encodeNames(dc.columns)
+			dc.constraint.TargetColumnNames = encodeNames(dc.targets)
+			info.addConstraint(&dc.constraint)
+
+			switch dc.constraint.ConstraintType {
+			case "p", "u":
+				info.Indexes = append(info.Indexes, &Index{
+					Name:      dc.constraint.ConstraintName,
+					Columns:   dc.columns,
+					IsUnique:  true,
+					IsPrimary: dc.constraint.ConstraintType == "p",
+					Method:    "btree",
+				})
+			}
+		}
+	}
+
+	for _, di := range p.indexes {
+		if info, ok := p.tables[di.table]; ok {
+			info.Indexes = append(info.Indexes, di.index)
+		}
+	}
+
+	for _, info := range p.tables {
+		sort.SliceStable(info.Indexes, func(i, j int) bool {
+			return info.Indexes[i].Name < info.Indexes[j].Name
+		})
+		info.addUniqueIndexes()
+		if info.PartitionOf != nil {
+			if parent, ok := p.tables[tableKey(info.PartitionOf.Schema, info.PartitionOf.Name)]; ok {
+				parent.Partitions = append(parent.Partitions, TableName{Schema: info.Schema, Name: info.Name, IsPartition: true})
+			}
+		}
+	}
+
+	for _, dc := range p.comments {
+		p.applyComment(dc)
+	}
+
+	for _, info := range p.tables {
+		sort.Slice(info.Partitions, func(i, j int) bool {
+			return info.Partitions[i].Name < info.Partitions[j].Name
+		})
+		model.Tables = append(model.Tables, *info)
+	}
+	sort.Slice(model.Tables, func(i, j int) bool {
+		a, b := &model.Tables[i], &model.Tables[j]
+		return tableKey(a.Schema, a.Name) < tableKey(b.Schema, b.Name)
+	})
+	model.Types = p.types
+
+	return
+}
+
+func (p *ddlParser) inheritColumns(key string, done map[string]bool) {
+	info := p.tables[key]
+	if done[key] {
+		return
+	}
+	done[key] = true
+
+	parents := info.Inherits
+	if info.PartitionOf != nil {
+		parents = []TableName{*info.PartitionOf}
+	}
+	if len(parents) == 0 {
+		return
+	}
+
+	own := []*Column{}
+	for _, column := range info.Columns {
+		own = append(own, column)
+	}
+	sort.Slice(own, func(i, j int) bool {
+		return own[i].OrdinalPosition < own[j].OrdinalPosition
+	})
+
+	columns := Columns{}
+	position := 0
+	for _, tn := range parents {
+		pk := tableKey(tn.Schema, tn.Name)
+		parent, ok := p.tables[pk]
+		if !ok {
+			continue
+		}
+		p.inheritColumns(pk, done)
+
+		inherited := []*Column{}
+		for _, column := range parent.Columns {
+			inherited = append(inherited, column)
+		}
+		sort.Slice(inherited, func(i, j int) bool {
+			return inherited[i].OrdinalPosition < inherited[j].OrdinalPosition
+		})
+		for _, column := range inherited {
+			if _, ok := columns[column.ColumnName]; ok {
+				continue
+			}
+			clone := *column
+			clone.TableSchema = info.Schema
+			clone.TableName = info.Name
+			clone.IsInherited = info.PartitionOf == nil
+			position += 1
+			clone.OrdinalPosition = position
+			if local, ok := info.Columns[column.ColumnName]; ok {
+				clone.ColumnDefault = local.ColumnDefault
+				if local.IsNullable == "NO" {
+					clone.IsNullable = "NO"
+				}
+			}
+			columns[clone.ColumnName] = &clone
+			p.columnTypes[&clone] = p.columnTypes[column]
+		}
+	}
+	for _, column := range own {
+		if _, ok := columns[column.ColumnName]; ok {
+			delete(p.columnTypes, column)
+			continue
+		}
+		position += 1
+		column.OrdinalPosition = position
+		columns[column.ColumnName] = column
+	}
+	info.Columns = columns
+}
+
+func (p *ddlParser) inheritChecks(key string, done map[string]bool) {
+	info := p.tables[key]
+	if done[key] {
+		return
+	}
+	done[key] = true
+
+	parents := info.Inherits
+	if info.PartitionOf != nil {
+		parents = []TableName{*info.PartitionOf}
+	}
+	for _, tn := range parents {
+		pk := tableKey(tn.Schema, tn.Name)
+		if _, ok := p.tables[pk]; !ok {
+			continue
+		}
+		p.inheritChecks(pk, done)
+
+		for _, dc := range p.constraints {
+			if dc.table != pk || dc.constraint.ConstraintType != "c" || strings.HasSuffix(dc.constraint.Definition, " NO INHERIT") {
+				continue
+			}
+			if slices.ContainsFunc(p.constraints, func(own *ddlConstraint) bool {
+				return own.table == key && own.constraint.ConstraintName == dc.constraint.ConstraintName
+			}) {
+				continue
+			}
+			clone := *dc
+			clone.table = key
+			clone.constraint.TableSchema = info.Schema
+			clone.constraint.TableName = info.Name
+			p.constraints = append(p.constraints, &clone)
+		}
+	}
+}
+
+func (p *ddlParser) applyComment(dc *ddlComment) {
+	switch dc.kind {
+	case "table":
+		schema, n := p.qualify(dc.names)
+		if info, ok := p.tables[tableKey(schema, n)]; ok {
+			info.Comment = dc.text
+		}
+	case "column":
+		if len(dc.names) < 2 {
+			return
+		}
+		schema, n := p.qualify(dc.names[:len(dc.names)-1])
+		if info, ok := p.tables[tableKey(schema, n)]; ok {
+			if column, ok := info.Columns[dc.names[len(dc.names)-1]]; ok {
+				column.Comment = dc.text
+			}
+		}
+	case "constraint":
+		schema, n := p.qualify(dc.on)
+		info, ok := p.tables[tableKey(schema, n)]
+		if !ok {
+			return
+		}
+		name := dc.names[len(dc.names)-1]
+		if info.PrimaryKeyName == name {
+			info.PrimaryKeyComment = dc.text
+		}
+		for _, fk := range info.ForeignKeys {
+			if fk.ConstraintName == name {
+				fk.Comment = dc.text
+			}
+		}
+		for _, key := range info.UniqueKeys {
+			if key.Name == name {
+				key.Comment = dc.text
+			}
+		}
+		for _, con := range info.Constraints {
+			if con.Name == name {
+				con.Comment = dc.text
+			}
+		}
+	case "index":
+		schema, n := p.qualify(dc.names)
+		for _, info := range p.tables {
+			if info.Schema != schema {
+				continue
+			}
+			for _, ix := range info.Indexes {
+				if ix.Name == n {
+					ix.Comment = dc.text
+				}
+			}
+			for _, key := range info.UniqueKeys {
+				if key.Name == n && key.IsIndex {
+					key.Comment = dc.text
+				}
+			}
+		}
+	case "type":
+		schema, n := p.qualify(dc.names)
+		if enum, ok := p.types.Enums[tableKey(schema, n)]; ok {
+			enum.Comment = dc.text
+		}
+		if domain, ok := p.types.Domains[tableKey(schema, n)]; ok {
+			domain.Comment = dc.text
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func ddlTables(t *testing.T, path string) (model Model, tables map[string]*TableInfo) {
+	t.Helper()
+	source := &DDLSource{Path: path}
+	model, err := source.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables = map[string]*TableInfo{}
+	for i := range model.Tables {
+		info := &model.Tables[i]
+		tables[tableKey(info.Schema, info.Name)] = info
+	}
+	return
+}
+
+func TestDDLSourcePgDump(t *testing.T) {
+	model, tables := ddlTables(t, filepath.Join("testdata", "pg_dump.sql"))
+	if model.Database != "pg_dump" {
+		t.Errorf("Database = %q, want %q", model.Database, "pg_dump")
+	}
+
+	var keys []string
+	for key := range tables {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	want := []string{"billing.invoices", "public.audited", "public.orders", "public.orders_2024", "public.users"}
+	if !slices.Equal(keys, want) {
+		t.Fatalf("tables = %v, want %v", keys, want)
+	}
+
+	columns := []struct {
+		table     string
+		column    string
+		position  int
+		typ       string
+		nullable  string
+		dflt      string
+		inherited bool
+	}{
+		{"public.users", "created_at", 1, "timestamptz", "NO", "now()", true},
+		{"public.users", "updated_at", 2, "timestamptz", "YES", "", true},
+		{"public.users", "id", 3, "bigint", "NO", "nextval('public.users_id_seq'::regclass)", false},
+		{"public.users", "email", 4, "varchar(320)", "NO", "", false},
+		{"public.users", "name", 5, "varchar(80)", "YES", "'anon'::character varying", false},
+		{"public.users", "Weird Col", 7, "integer", "YES", "", false},
+		{"public.orders", "state", 3, "order_state", "NO", "'new'::public.order_state", false},
+		{"public.orders", "amount", 4, "numeric(12,2)", "YES", "", false},
+		{"public.orders_2024", "placed_at", 5, "timestamptz", "NO", "", false},
+		{"billing.invoices", "id", 1, "integer", "NO", "", false},
+	}
+	for _, tt := range columns {
+		info := tables[tt.table]
+		c, ok := info.Columns[tt.column]
+		if !ok {
+			t.Errorf("%s: missing column %s", tt.table, tt.column)
+			continue
+		}
+		if c.OrdinalPosition != tt.position {
+			t.Errorf("%s.%s: position %d, want %d", tt.table, tt.column, c.OrdinalPosition, tt.position)
+		}
+		if typ := c.FormatType(nil); typ != tt.typ {
+			t.Errorf("%s.%s: type %q, want %q", tt.table, tt.column, typ, tt.typ)
+		}
+		if c.IsNullable != tt.nullable {
+			t.Errorf("%s.%s: nullable %q, want %q", tt.table, tt.column, c.IsNullable, tt.nullable)
+		}
+		if c.ColumnDefault != tt.dflt {
+			t.Errorf("%s.%s: default %q, want %q", tt.table, tt.column, c.ColumnDefault, tt.dflt)
+		}
+		if c.IsInherited != tt.inherited {
+			t.Errorf("%s.%s: inherited %v, want %v", tt.table, tt.column, c.IsInherited, tt.inherited)
+		}
+	}
+
+	users := tables["public.users"]
+	if c := users.Columns["tags"]; c.DataType != "ARRAY" || c.FormatType(nil) != "varchar(20)[]" {
+		t.Errorf("users.tags: %s %s, want varchar(20)[]", c.DataType, c.FormatType(nil))
+	}
+	if c := users.Columns["email"]; c.DomainName != "email" || c.DomainSchema != "public" {
+		t.Errorf("users.email: domain %s.%s, want public.email", c.DomainSchema, c.DomainName)
+	}
+	if !slices.Equal(users.PrimaryKey, []string{"id"}) || users.PrimaryKeyName != "users_pkey" {
+		t.Errorf("users primary key = %s %v", users.PrimaryKeyName, users.PrimaryKey)
+	}
+	if len(users.Inherits) != 1 || users.Inherits[0].Name != "audited" {
+		t.Errorf("users inherits %v, want audited", users.Inherits)
+	}
+	if users.Comment != "Registered users" || users.Columns["name"].Comment != "Display name" {
+		t.Errorf("users comments = %q, %q", users.Comment, users.Columns["name"].Comment)
+	}
+	if !slices.ContainsFunc(users.UniqueKeys, func(key *UniqueKey) bool { return key.Name == "users_email_key" && !key.IsIndex }) {
+		t.Errorf("users unique keys = %v, want users_email_key", users.UniqueKeys)
+	}
+	i := slices.IndexFunc(users.Indexes, func(ix *Index) bool { return ix.Name == "users_lower_name_idx" })
+	if i < 0 || !users.Indexes[i].IsUnique || users.Indexes[i].Comment != "case-insensitive names" {
+		t.Errorf("users indexes = %v, want a commented users_lower_name_idx", users.Indexes)
+	}
+
+	orders := tables["public.orders"]
+	if orders.PartitionKey != "RANGE (placed_at)" {
+		t.Errorf("orders partition key = %q", orders.PartitionKey)
+	}
+	if len(orders.Partitions) != 1 || orders.Partitions[0].Name != "orders_2024" {
+		t.Errorf("orders partitions = %v", orders.Partitions)
+	}
+	partition := tables["public.orders_2024"]
+	if partition.PartitionOf == nil || partition.PartitionOf.Name != "orders" {
+		t.Errorf("orders_2024 partition of %v, want orders", partition.PartitionOf)
+	}
+	if partition.PartitionBound != "FOR VALUES FROM ('2024-01-01 00:00:00+00') TO ('2025-01-01 00:00:00+00')" {
+		t.Errorf("orders_2024 bound = %q", partition.PartitionBound)
+	}
+	if len(orders.Constraints) != 1 || orders.Constraints[0].Name != "amount_positive" {
+		t.Errorf("orders constraints = %v, want amount_positive", orders.Constraints)
+	}
+	i = slices.IndexFunc(orders.Indexes, func(ix *Index) bool { return ix.Name == "orders_state_idx" })
+	if i < 0 || orders.Indexes[i].Method != "hash" || orders.Indexes[i].Predicate != "(state <> 'paid'::public.order_state)" {
+		t.Errorf("orders indexes = %v, want a partial hash orders_state_idx", orders.Indexes)
+	}
+
+	if len(orders.ForeignKeys) != 1 {
+		t.Fatalf("orders has %d foreign keys, want 1", len(orders.ForeignKeys))
+	}
+	fk := orders.ForeignKeys[0]
+	if fk.TableName != "users" || fk.DeleteRule != "CASCADE" || !fk.IsDeferrable || !fk.InitiallyDeferred || fk.Comment != "owner" {
+		t.Errorf("orders_user_id_fkey = %+v", fk)
+	}
+
+	invoices := tables["billing.invoices"]
+	if c := invoices.Columns["id"]; c.IsIdentity != "YES" || c.IdentityGeneration != "ALWAYS" {
+		t.Errorf("invoices.id identity = %q %q, want YES ALWAYS", c.IsIdentity, c.IdentityGeneration)
+	}
+	if len(invoices.ForeignKeys) != 1 {
+		t.Fatalf("invoices has %d foreign keys, want 1", len(invoices.ForeignKeys))
+	}
+	fk = invoices.ForeignKeys[0]
+	if fk.TableSchema != "public" || fk.TableName != "orders" || !slices.Equal(fk.ReferencedColumns, []string{"id", "placed_at"}) || fk.DeleteRule != "SET NULL" {
+		t.Errorf("invoices_order_fkey = %+v", fk)
+	}
+
+	enum, ok := model.Types.Enums[tableKey("public", "order_state")]
+	if !ok || !slices.Equal(enum.Labels, []string{"new", "paid", "it's shipped"}) {
+		t.Errorf("order_state = %+v", enum)
+	}
+	domain, ok := model.Types.Domains[tableKey("public", "email")]
+	if !ok || domain.BaseType != "varchar(320)" {
+		t.Errorf("email domain = %+v", domain)
+	}
+}
+
+func TestDDLSourceMigrations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"001_init.sql": `
+			CREATE TABLE users (id serial PRIMARY KEY, name text, junk int);
+			CREATE TABLE posts (
+				id bigserial,
+				author int REFERENCES users,
+				title varchar(200) NOT NULL DEFAULT '',
+				CONSTRAINT posts_pkey PRIMARY KEY (id)
+			);
+			CREATE TABLE copied (LIKE users);
+		`,
+		"002_alter.sql": `
+			ALTER TABLE users ADD COLUMN email text NOT NULL, DROP COLUMN junk;
+			ALTER TABLE users RENAME COLUMN name TO display_name;
+			ALTER TABLE posts ALTER COLUMN title DROP DEFAULT, ALTER COLUMN title TYPE text, ALTER COLUMN author SET NOT NULL;
+			ALTER TABLE posts DROP CONSTRAINT posts_author_fkey;
+			ALTER TABLE posts ADD CONSTRAINT posts_author_fkey FOREIGN KEY (author) REFERENCES users (id) ON DELETE CASCADE;
+			CREATE UNIQUE INDEX users_email_idx ON users (email);
+		`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, tables := ddlTables(t, dir)
+	users := tables["public.users"]
+	posts := tables["public.posts"]
+
+	var names []string
+	for _, c := range columnsByPosition(users) {
+		names = append(names, c.ColumnName)
+	}
+	if !slices.Equal(names, []string{"id", "display_name", "email"}) {
+		t.Errorf("users columns = %v", names)
+	}
+	if c := users.Columns["id"]; c.ColumnDefault != "nextval('users_id_seq'::regclass)" || c.IsNullable != "NO" || c.FormatType(nil) != "integer" {
+		t.Errorf("users.id = %s %s default %q", c.FormatType(nil), c.IsNullable, c.ColumnDefault)
+	}
+	if c := posts.Columns["id"]; c.FormatType(nil) != "bigint" || c.ColumnDefault != "nextval('posts_id_seq'::regclass)" {
+		t.Errorf("posts.id = %s default %q", c.FormatType(nil), c.ColumnDefault)
+	}
+	if c := posts.Columns["title"]; c.FormatType(nil) != "text" || c.ColumnDefault != "" || c.IsNullable != "NO" {
+		t.Errorf("posts.title = %s %s default %q", c.FormatType(nil), c.IsNullable, c.ColumnDefault)
+	}
+	if c := posts.Columns["author"]; c.IsNullable != "NO" {
+		t.Errorf("posts.author nullable %q, want NO", c.IsNullable)
+	}
+	if len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].DeleteRule != "CASCADE" || !slices.Equal(posts.ForeignKeys[0].ReferencedColumns, []string{"id"}) {
+		t.Errorf("posts foreign keys = %v", posts.ForeignKeys)
+	}
+	if !slices.ContainsFunc(users.UniqueKeys, func(key *UniqueKey) bool { return key.Name == "users_email_idx" && key.IsIndex }) {
+		t.Errorf("users unique keys = %v, want users_email_idx", users.UniqueKeys)
+	}
+	if copied := tables["public.copied"]; copied == nil || len(copied.Columns) != 0 {
+		t.Errorf("copied = %+v, want a table without columns", copied)
+	}
+}
+
+func TestDDLSourceInheritedChecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	src := `
+		CREATE TABLE parent (id int, n int CHECK (n > 0), CONSTRAINT local_only CHECK (id > 0) NO INHERIT) PARTITION BY RANGE (id);
+		CREATE TABLE part PARTITION OF parent FOR VALUES FROM (0) TO (10);
+		CREATE TABLE base (a int CONSTRAINT a_check CHECK (a <> 0));
+		CREATE TABLE child (b int, CONSTRAINT a_check CHECK (a <> 0)) INHERITS (base);
+		CREATE TABLE grandchild () INHERITS (child);
+	`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, tables := ddlTables(t, path)
+
+	tests := []struct {
+		table string
+		want  []string
+	}{
+		{"public.parent", []string{"local_only CHECK (id > 0) NO INHERIT", "parent_n_check CHECK (n > 0)"}},
+		{"public.part", []string{"parent_n_check CHECK (n > 0)"}},
+		{"public.child", []string{"a_check CHECK (a <> 0)"}},
+		{"public.grandchild", []string{"a_check CHECK (a <> 0)"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, con := range tables[tt.table].Constraints {
+			got = append(got, con.Name+" "+con.Definition)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s constraints = %q, want %q", tt.table, got, tt.want)
+		}
+	}
+}
+
+func columnsByPosition(info *TableInfo) (columns []*Column) {
+	for _, c := range info.Columns {
+		columns = append(columns, c)
+	}
+	slices.SortFunc(columns, func(a, b *Column) int {
+		return a.OrdinalPosition - b.OrdinalPosition
+	})
+	return
+}
+
+func TestDDLSourceSerialDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	src := `
+		CREATE SCHEMA billing;
+		CREATE TABLE billing.invoices (id serial PRIMARY KEY, number bigserial);
+		CREATE TABLE public.users (id serial PRIMARY KEY);
+		CREATE TABLE notes (id smallserial);
+	`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, tables := ddlTables(t, path)
+
+	tests := []struct {
+		table  string
+		column string
+		want   string
+	}{
+		{"billing.invoices", "id", "nextval('billing.invoices_id_seq'::regclass)"},
+		{"billing.invoices", "number", "nextval('billing.invoices_number_seq'::regclass)"},
+		{"public.users", "id", "nextval('users_id_seq'::regclass)"},
+		{"public.notes", "id", "nextval('notes_id_seq'::regclass)"},
+	}
+	for _, tt := range tests {
+		if c := tables[tt.table].Columns[tt.column]; c.ColumnDefault != tt.want || c.IsNullable != "NO" {
+			t.Errorf("%s.%s = %s default %q, want NOT NULL default %q", tt.table, tt.column, c.IsNullable, c.ColumnDefault, tt.want)
+		}
+	}
+}
+
+func TestModelFilterSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	src := `
+		CREATE TABLE public.users (id int);
+		CREATE TABLE billing.invoices (id int);
+		CREATE TABLE audit.events (id int);
+	`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		schemas []string
+		exclude []string
+		want    []string
+	}{
+		{nil, nil, []string{"audit.events", "billing.invoices", "public.users"}},
+		{[]string{"public", "billing"}, nil, []string{"billing.invoices", "public.users"}},
+		{nil, []string{"audit"}, []string{"billing.invoices", "public.users"}},
+		{[]string{"public", "audit"}, []string{"audit"}, []string{"public.users"}},
+	}
+	for _, tt := range tests {
+		model, _ := ddlTables(t, path)
+		model.FilterSchemas(tt.schemas, tt.exclude)
+		var got []string
+		for _, info := range model.Tables {
+			got = append(got, tableKey(info.Schema, info.Name))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FilterSchemas(%v, %v) = %v, want %v", tt.schemas, tt.exclude, got, tt.want)
+		}
+	}
+}
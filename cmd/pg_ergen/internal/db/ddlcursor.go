@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+type cursor struct {
+	st  *statement
+	i   int
+	end int
+}
+
+func (c *cursor) sub(from int, to int) *cursor {
+	return &cursor{c.st, from, to}
+}
+
+func (c *cursor) done() bool {
+	return c.i >= c.end
+}
+
+func (c *cursor) peek() *token {
+	if c.done() {
+		return nil
+	}
+	return &c.st.tokens[c.i]
+}
+
+func (c *cursor) is(words ...string) bool {
+	t := c.peek()
+	return t != nil && t.is(words...)
+}
+
+func (c *cursor) accept(words ...string) bool {
+	if c.i+len(words) > c.end {
+		return false
+	}
+	for k, w := range words {
+		if !c.st.tokens[c.i+k].is(w) {
+			return false
+		}
+	}
+	c.i += len(words)
+	return true
+}
+
+func (c *cursor) acceptPunct(p string) bool {
+	t := c.peek()
+	if t != nil && t.punct(p) {
+		c.i += 1
+		return true
+	}
+	return false
+}
+
+func (c *cursor) errorf(format string, args ...any) error {
+	near := "end of statement"
+	if t := c.peek(); t != nil {
+		near = fmt.Sprintf("%q", t.text)
+	}
+	return fmt.Errorf(format+" near %s", append(args, near)...)
+}
+
+func (c *cursor) identifier() (name string, err error) {
+	t := c.peek()
+	if t == nil || (t.kind != tokenWord && t.kind != tokenIdent) {
+		err = c.errorf("identifier expected")
+		return
+	}
+	c.i += 1
+	return t.name(), nil
+}
+
+func (c *cursor) names() (names []string, err error) {
+	for {
+		var name string
+		name, err = c.identifier()
+		if err != nil {
+			return
+		}
+		names = append(names, name)
+		if !c.acceptPunct(".") {
+			return
+		}
+	}
+}
+
+func (c *cursor) str() (s string, err error) {
+	t := c.peek()
+	if t == nil || t.kind != tokenString {
+		err = c.errorf("string expected")
+		return
+	}
+	c.i += 1
+	return t.text, nil
+}
+
+func (c *cursor) group() (inner *cursor, err error) {
+	if !c.acceptPunct("(") {
+		err = c.errorf("( expected")
+		return
+	}
+	from := c.i
+	depth := 1
+	for ; c.i < c.end; c.i += 1 {
+		t := &c.st.tokens[c.i]
+		if t.punct("(") {
+			depth += 1
+		} else if t.punct(")") {
+			depth -= 1
+			if depth == 0 {
+				inner = c.sub(from, c.i)
+				c.i += 1
+				return
+			}
+		}
+	}
+	err = c.errorf("unbalanced parentheses")
+	return
+}
+
+func (c *cursor) split() (parts []*cursor) {
+	from := c.i
+	depth := 0
+	for k := c.i; k < c.end; k += 1 {
+		t := &c.st.tokens[k]
+		switch {
+		case t.punct("(") || t.punct("["):
+			depth += 1
+		case t.punct(")") || t.punct("]"):
+			depth -= 1
+		case t.punct(",") && depth == 0:
+			parts = append(parts, c.sub(from, k))
+			from = k + 1
+		}
+	}
+	if from < c.end {
+		parts = append(parts, c.sub(from, c.end))
+	}
+	return
+}
+
+func (c *cursor) until(words ...string) string {
+	from := c.i
+	depth := 0
+	for ; c.i < c.end; c.i += 1 {
+		t := &c.st.tokens[c.i]
+		if t.punct("(") || t.punct("[") {
+			depth += 1
+		} else if t.punct(")") || t.punct("]") {
+			depth -= 1
+		} else if depth == 0 && t.is(words...) {
+			break
+		}
+	}
+	return c.st.text(from, c.i)
+}
+
+func (c *cursor) rest() string {
+	text := c.st.text(c.i, c.end)
+	c.i = c.end
+	return text
+}
+
+func (c *cursor) columnNames() (names []string, err error) {
+	inner, err := c.group()
+	if err != nil {
+		return
+	}
+	for _, part := range inner.split() {
+		t := part.peek()
+		if t != nil && (t.kind == tokenWord || t.kind == tokenIdent) && part.end-part.i == 1 {
+			names = append(names, t.name())
+		} else {
+			names = append(names, strings.TrimSpace(part.rest()))
+		}
+	}
+	return
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	tokenWord = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+	end  int
+}
+
+func (t *token) is(words ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *token) punct(p string) bool {
+	return t.kind == tokenPunct && t.text == p
+}
+
+func (t *token) name() string {
+	if t.kind == tokenWord {
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+type statement struct {
+	src    string
+	line   int
+	tokens []token
+}
+
+func (s *statement) text(from int, to int) string {
+	if from >= to {
+		return ""
+	}
+	return strings.TrimSpace(s.src[s.tokens[from].pos:s.tokens[to-1].end])
+}
+
+func splitStatements(src string) (statements []*statement, err error) {
+	current := &statement{src: src, line: 1}
+	line := 1
+	i := 0
+	push := func(t token) {
+		if len(current.tokens) == 0 {
+			current.line = line
+		}
+		current.tokens = append(current.tokens, t)
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line += 1
+			i += 1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i += 1
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i += 1
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			depth := 0
+			for i < len(src) {
+				if strings.HasPrefix(src[i:], "/*") {
+					depth += 1
+					i += 2
+				} else if strings.HasPrefix(src[i:], "*/") {
+					depth -= 1
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					if src[i] == '\n' {
+						line += 1
+					}
+					i += 1
+				}
+			}
+		case c == ';':
+			if len(current.tokens) > 0 {
+				statements = append(statements, current)
+			}
+			current = &statement{src: src}
+			i += 1
+		case c == '\'' || ((c == 'E' || c == 'e') && i+1 < len(src) && src[i+1] == '\''):
+			start := i
+			escape := c != '\''
+			if escape {
+				i += 1
+			}
+			i += 1
+			var sb strings.Builder
+			for {
+				if i >= len(src) {
+					err = fmt.Errorf("line %d: unterminated string", line)
+					return
+				}
+				if src[i] == '\'' {
+					if i+1 < len(src) && src[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i += 1
+					break
+				}
+				if escape && src[i] == '\\' && i+1 < len(src) {
+					i += 1
+				}
+				if src[i] == '\n' {
+					line += 1
+				}
+				sb.WriteByte(src[i])
+				i += 1
+			}
+			push(token{tokenString, sb.String(), start, i})
+		case c == '$' && dollarTag(src[i:]) != "":
+			start := i
+			tag := dollarTag(src[i:])
+			i += len(tag)
+			end := strings.Index(src[i:], tag)
+			if end < 0 {
+				err = fmt.Errorf("line %d: unterminated %s string", line, tag)
+				return
+			}
+			body := src[i : i+end]
+			line += strings.Count(body, "\n")
+			i += end + len(tag)
+			push(token{tokenString, body, start, i})
+		case c == '"':
+			start := i
+			i += 1
+			var sb strings.Builder
+			for {
+				if i >= len(src) {
+					err = fmt.Errorf("line %d: unterminated identifier", line)
+					return
+				}
+				if src[i] == '"' {
+					if i+1 < len(src) && src[i+1] == '"' {
+						sb.WriteByte('"')
+						i += 2
+						continue
+					}
+					i += 1
+					break
+				}
+				sb.WriteByte(src[i])
+				i += 1
+			}
+			push(token{tokenIdent, sb.String(), start, i})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i += 1
+			}
+			push(token{tokenNumber, src[start:i], start, i})
+		case c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '$' || src[i] >= 0x80 || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i += 1
+			}
+			push(token{tokenWord, src[start:i], start, i})
+		case c == ':' && i+1 < len(src) && src[i+1] == ':':
+			push(token{tokenPunct, "::", i, i + 2})
+			i += 2
+		default:
+			push(token{tokenPunct, string(c), i, i + 1})
+			i += 1
+		}
+	}
+	if len(current.tokens) > 0 {
+		statements = append(statements, current)
+	}
+
+	return
+}
+
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i += 1 {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if !(c == '_' || unicode.IsLetter(rune(c)) || (i > 1 && unicode.IsDigit(rune(c)))) {
+			return ""
+		}
+	}
+	return ""
+}
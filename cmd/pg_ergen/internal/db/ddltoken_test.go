@@ -0,0 +1,131 @@
+package db
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		want  []string
+		lines []int
+		err   bool
+	}{
+		{
+			name:  "statements and line numbers",
+			src:   "CREATE TABLE a (id int);\n\nDROP TABLE a;",
+			want:  []string{"CREATE|TABLE|a|(|id|int|)", "DROP|TABLE|a"},
+			lines: []int{1, 3},
+		},
+		{
+			name:  "empty statements",
+			src:   ";;  ;\nSELECT 1;;",
+			want:  []string{"SELECT|1"},
+			lines: []int{2},
+		},
+		{
+			name:  "line comment",
+			src:   "-- drop; everything\nSELECT 1; -- trailing;",
+			want:  []string{"SELECT|1"},
+			lines: []int{2},
+		},
+		{
+			name:  "nested block comment",
+			src:   "/* outer /* inner; */ still; comment */\nSELECT 1;",
+			want:  []string{"SELECT|1"},
+			lines: []int{2},
+		},
+		{
+			name:  "doubled quote",
+			src:   "SELECT 'it''s; fine';",
+			want:  []string{"SELECT|it's; fine"},
+			lines: []int{1},
+		},
+		{
+			name:  "escape string",
+			src:   `SELECT E'it\'s', e'a\\b';`,
+			want:  []string{`SELECT|it's|,|a\b`},
+			lines: []int{1},
+		},
+		{
+			name:  "dollar quoting",
+			src:   "CREATE FUNCTION f() RETURNS int AS $body$ BEGIN; RETURN $$1$$; END; $body$ LANGUAGE plpgsql;\nSELECT $$x;y$$;",
+			want:  []string{"CREATE|FUNCTION|f|(|)|RETURNS|int|AS| BEGIN; RETURN $$1$$; END; |LANGUAGE|plpgsql", "SELECT|x;y"},
+			lines: []int{1, 2},
+		},
+		{
+			name:  "positional parameter is not a dollar quote",
+			src:   "SELECT $1;",
+			want:  []string{"SELECT|$|1"},
+			lines: []int{1},
+		},
+		{
+			name:  "quoted identifier",
+			src:   `CREATE TABLE "My ""T""" ("a;b" int);`,
+			want:  []string{`CREATE|TABLE|My "T"|(|a;b|int|)`},
+			lines: []int{1},
+		},
+		{
+			name:  "cast and numbers",
+			src:   "SELECT 'x'::varchar(20), 1.5;",
+			want:  []string{"SELECT|x|::|varchar|(|20|)|,|1.5"},
+			lines: []int{1},
+		},
+		{
+			name:  "multi-line string keeps line count",
+			src:   "SELECT 'a\nb';\nSELECT 2;",
+			want:  []string{"SELECT|a\nb", "SELECT|2"},
+			lines: []int{1, 3},
+		},
+		{
+			name: "unterminated string",
+			src:  "SELECT 'oops;",
+			err:  true,
+		},
+		{
+			name: "unterminated dollar quote",
+			src:  "SELECT $tag$ oops;",
+			err:  true,
+		},
+		{
+			name: "unterminated identifier",
+			src:  `SELECT "oops;`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := splitStatements(tt.src)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			var lines []int
+			for _, st := range statements {
+				texts := make([]string, len(st.tokens))
+				for i, tok := range st.tokens {
+					texts[i] = tok.text
+				}
+				got = append(got, strings.Join(texts, "|"))
+				lines = append(lines, st.line)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("statements = %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(lines, tt.lines) {
+				t.Errorf("lines = %v, want %v", lines, tt.lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"slices"
+	"strings"
+)
+
+type typeSpec struct {
+	schema string
+	words  []string
+	args   []string
+	array  bool
+}
+
+var typeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"int8":        "bigint",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"int2":        "smallint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"bool":        "boolean",
+	"float":       "double precision",
+	"float8":      "double precision",
+	"float4":      "real",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"decimal":     "numeric",
+	"varbit":      "bit varying",
+	"timestamptz": "timestamp with time zone",
+	"timetz":      "time with time zone",
+}
+
+var serialTypes = []string{"serial", "serial4", "bigserial", "serial8", "smallserial", "serial2"}
+
+var builtinTypes = []string{
+	"integer", "bigint", "smallint", "boolean", "real", "double precision", "numeric",
+	"text", "character varying", "character", "bit", "bit varying", "bytea",
+	"date", "timestamp with time zone", "timestamp without time zone",
+	"time with time zone", "time without time zone", "interval",
+	"uuid", "json", "jsonb", "xml", "money", "inet", "cidr", "macaddr", "macaddr8",
+	"tsvector", "tsquery", "point", "line", "lseg", "box", "path", "polygon", "circle",
+	"oid", "regclass", "name",
+}
+
+var builtinUdtNames = map[string]string{
+	"integer":                     "int4",
+	"bigint":                      "int8",
+	"smallint":                    "int2",
+	"boolean":                     "bool",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	"bit varying":                 "varbit",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time with time zone":         "timetz",
+	"time without time zone":      "time",
+}
+
+func (c *cursor) typeSpec(stop ...string) (spec typeSpec, err error) {
+	for !c.done() {
+		t := c.peek()
+		switch {
+		case t.kind == tokenWord && t.is(stop...):
+			return
+		case t.kind == tokenWord || t.kind == tokenIdent:
+			c.i += 1
+			if c.acceptPunct(".") {
+				spec.schema = t.name()
+			} else if t.is("array") {
+				spec.array = true
+			} else {
+				spec.words = append(spec.words, t.name())
+			}
+		case t.punct("("):
+			var inner *cursor
+			inner, err = c.group()
+			if err != nil {
+				return
+			}
+			if spec.args == nil {
+				for _, part := range inner.split() {
+					spec.args = append(spec.args, part.rest())
+				}
+			}
+		case t.punct("["):
+			for !c.done() && !c.acceptPunct("]") {
+				c.i += 1
+			}
+			spec.array = true
+		default:
+			return
+		}
+	}
+	if len(spec.words) == 0 {
+		err = c.errorf("type expected")
+	}
+	return
+}
+
+func (spec *typeSpec) name() string {
+	return strings.Join(spec.words, " ")
+}
+
+func (spec *typeSpec) isSerial() bool {
+	return len(spec.schema) == 0 && len(spec.words) == 1 && slices.Contains(serialTypes, spec.words[0])
+}
+
+func (spec *typeSpec) canonical() (name string, fields string) {
+	if len(spec.words) == 0 {
+		return
+	}
+	switch spec.words[0] {
+	case "timestamp", "time":
+		zone := " without time zone"
+		if slices.Contains(spec.words, "with") {
+			zone = " with time zone"
+		}
+		return spec.words[0] + zone, ""
+	case "interval":
+		return "interval", strings.Join(spec.words[1:], " ")
+	case "character", "char", "bit":
+		base := spec.words[0]
+		if base == "char" {
+			base = "character"
+		}
+		if slices.Contains(spec.words, "varying") {
+			return base + " varying", ""
+		}
+		return base, ""
+	}
+	name = spec.name()
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	return
+}
+
+func (spec *typeSpec) builtin() bool {
+	if len(spec.schema) > 0 && spec.schema != "pg_catalog" {
+		return false
+	}
+	name, _ := spec.canonical()
+	return slices.Contains(builtinTypes, name)
+}
+
+func (spec *typeSpec) apply(column *Column, p *ddlParser) {
+	if !spec.builtin() {
+		schema := spec.schema
+		if len(schema) == 0 {
+			schema = p.searchPath
+		}
+		key := tableKey(schema, spec.name())
+		if domain, ok := p.domains[key]; ok && !spec.array {
+			domain.apply(column, p)
+			column.DomainSchema = schema
+			column.DomainName = spec.name()
+			return
+		}
+		column.DataType = "USER-DEFINED"
+		column.UdtSchema = schema
+		column.UdtName = spec.name()
+		if spec.array {
+			column.DataType = "ARRAY"
+			column.UdtName = "_" + column.UdtName
+		}
+		return
+	}
+
+	name, fields := spec.canonical()
+	udt := name
+	if n, ok := builtinUdtNames[name]; ok {
+		udt = n
+	}
+	column.UdtSchema = "pg_catalog"
+	column.UdtName = udt
+	column.DataType = name
+	if spec.array {
+		column.DataType = "ARRAY"
+		column.UdtName = "_" + udt
+	}
+
+	switch name {
+	case "character varying", "bit varying":
+		if len(spec.args) > 0 {
+			column.CharacterMaximumLength = spec.args[0]
+		}
+	case "character", "bit":
+		column.CharacterMaximumLength = "1"
+		if len(spec.args) > 0 {
+			column.CharacterMaximumLength = spec.args[0]
+		}
+	case "numeric":
+		if len(spec.args) > 0 {
+			column.NumericPrecision = spec.args[0]
+			column.NumericScale = "0"
+		}
+		if len(spec.args) > 1 {
+			column.NumericScale = spec.args[1]
+		}
+	case "timestamp with time zone", "timestamp without time zone", "time with time zone", "time without time zone":
+		column.DatetimePrecision = "6"
+		if len(spec.args) > 0 {
+			column.DatetimePrecision = spec.args[0]
+		}
+	case "interval":
+		column.IntervalType = strings.ToUpper(fields)
+	}
+}
+
+func (spec *typeSpec) String() string {
+	var column Column
+	spec.apply(&column, &ddlParser{searchPath: "public"})
+	return column.FormatType(nil)
+}
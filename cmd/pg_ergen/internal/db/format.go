@@ -0,0 +1,94 @@
+package db
+
+import "strings"
+
+var dataTypeNames = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"bit varying":                 "varbit",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time with time zone":         "timetz",
+	"time without time zone":      "time",
+}
+
+var udtNames = map[string]string{
+	"int2":   "smallint",
+	"int4":   "integer",
+	"int8":   "bigint",
+	"float4": "real",
+	"float8": "double precision",
+	"bool":   "boolean",
+	"bpchar": "char",
+}
+
+var arrayElementTypes = map[string]string{
+	"varchar":     "character varying",
+	"bpchar":      "character",
+	"bit":         "bit",
+	"varbit":      "bit varying",
+	"numeric":     "numeric",
+	"timestamptz": "timestamp with time zone",
+	"timestamp":   "timestamp without time zone",
+	"timetz":      "time with time zone",
+	"time":        "time without time zone",
+	"interval":    "interval",
+}
+
+func (c *Column) baseType() (name string, args string) {
+	switch c.DataType {
+	case "ARRAY":
+		name = strings.TrimPrefix(c.UdtName, "_")
+		args = c.typeArgs(arrayElementTypes[name])
+		if n, ok := udtNames[name]; ok {
+			name = n
+		}
+		return
+	case "USER-DEFINED":
+		name = c.UdtName
+		if len(c.UdtSchema) > 0 && c.UdtSchema != "public" && c.UdtSchema != "pg_catalog" {
+			name = c.UdtSchema + "." + name
+		}
+		return
+	}
+
+	name = c.DataType
+	args = c.typeArgs(name)
+	if n, ok := dataTypeNames[name]; ok {
+		name = n
+	}
+	return
+}
+
+func (c *Column) typeArgs(dataType string) (args string) {
+	switch dataType {
+	case "character varying", "character", "bit", "bit varying":
+		if len(c.CharacterMaximumLength) > 0 {
+			args = "(" + c.CharacterMaximumLength + ")"
+		}
+	case "numeric":
+		if len(c.NumericPrecision) > 0 {
+			args = "(" + c.NumericPrecision + "," + c.NumericScale + ")"
+		}
+	case "timestamp with time zone", "timestamp without time zone", "time with time zone", "time without time zone":
+		if len(c.DatetimePrecision) > 0 && c.DatetimePrecision != "6" {
+			args = "(" + c.DatetimePrecision + ")"
+		}
+	case "interval":
+		if len(c.IntervalType) > 0 {
+			args = " " + strings.ToLower(c.IntervalType)
+		}
+	}
+	return
+}
+
+func (c *Column) FormatType(abbreviations map[string]string) string {
+	name, args := c.baseType()
+	if abbr, ok := abbreviations[name]; ok {
+		name = abbr
+	}
+	if c.DataType == "ARRAY" {
+		return name + args + "[]"
+	}
+	return name + args
+}
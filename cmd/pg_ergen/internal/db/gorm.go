@@ -0,0 +1,847 @@
+package db
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/inflection"
+	"gorm.io/gorm/schema"
+)
+
+const gormModelSource = `package gorm
+
+import (
+	"database/sql"
+	"time"
+)
+
+type DeletedAt sql.NullTime
+
+type Model struct {
+	ID        uint ` + "`gorm:\"primarykey\"`" + `
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt DeletedAt ` + "`gorm:\"index\"`" + `
+}
+`
+
+var gormConstraintName = regexp.MustCompile("^[A-Za-z-_]+$")
+
+type GormSource struct {
+	Path string
+}
+
+type gormPackage struct {
+	dir    string
+	path   string
+	name   string
+	types  map[string]*gormType
+	tables map[string]string
+}
+
+type gormImport struct {
+	name string
+	path string
+}
+
+type gormType struct {
+	pkg      *gormPackage
+	name     string
+	expr     ast.Expr
+	imports  []gormImport
+	doc      string
+	model    bool
+	embedded bool
+
+	fields  []*gormField
+	table   string
+	info    *TableInfo
+	primary []string
+	columns map[string]string
+}
+
+type gormField struct {
+	name     string
+	column   string
+	expr     ast.Expr
+	owner    *gormType
+	tag      string
+	settings map[string]string
+
+	typeName string
+	target   *gormType
+	many     bool
+}
+
+type gormIndex struct {
+	index      *Index
+	columns    []string
+	priorities []int
+}
+
+type gormLoader struct {
+	p        *ddlParser
+	naming   schema.NamingStrategy
+	packages []*gormPackage
+	byPath   map[string]*gormPackage
+	model    *gormType
+}
+
+func (s *GormSource) Snapshot() (model Model, err error) {
+	root, recursive := strings.CutSuffix(s.Path, "/...")
+	dirs := []string{root}
+	if recursive {
+		dirs, err = goPackageDirs(root)
+		if err != nil {
+			return
+		}
+	}
+
+	g := newGormLoader()
+	for _, dir := range dirs {
+		err = g.parseDir(dir)
+		if err != nil {
+			return
+		}
+	}
+	models := g.models()
+	for _, t := range models {
+		g.table(t)
+	}
+	g.relations(models)
+
+	model = g.p.model()
+	model.Database = root
+	if abs, e := filepath.Abs(root); e == nil {
+		model.Database = filepath.Base(abs)
+	}
+	model.SnapshotAt = time.Now()
+
+	return
+}
+
+func goPackageDirs(root string) (dirs []string, err error) {
+	err = filepath.WalkDir(root, func(fn string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if fn != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, fn)
+		return nil
+	})
+	return
+}
+
+func newGormLoader() *gormLoader {
+	g := &gormLoader{p: newDDLParser(), byPath: map[string]*gormPackage{}}
+	file, err := parser.ParseFile(gotoken.NewFileSet(), "gorm.go", gormModelSource, parser.SkipObjectResolution)
+	if err != nil {
+		panic(err)
+	}
+	pkg := &gormPackage{path: "gorm.io/gorm", name: "gorm", types: map[string]*gormType{}, tables: map[string]string{}}
+	g.parseFile(pkg, file)
+	g.model = pkg.types["Model"]
+	return g
+}
+
+func (g *gormLoader) parseDir(dir string) (err error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return
+	}
+	sort.Strings(files)
+
+	path, err := packagePath(dir)
+	if err != nil {
+		return
+	}
+
+	fset := gotoken.NewFileSet()
+	packages := map[string]*gormPackage{}
+	for _, fn := range files {
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
+		}
+		var file *ast.File
+		file, err = parser.ParseFile(fset, fn, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return
+		}
+		pkg, ok := packages[file.Name.Name]
+		if !ok {
+			pkg = &gormPackage{dir: dir, name: file.Name.Name, types: map[string]*gormType{}, tables: map[string]string{}}
+			packages[pkg.name] = pkg
+			g.packages = append(g.packages, pkg)
+			if len(path) > 0 {
+				pkg.path = path
+				g.byPath[path] = pkg
+			}
+		}
+		g.parseFile(pkg, file)
+	}
+
+	return
+}
+
+func packagePath(dir string) (path string, err error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	for root := abs; ; {
+		src, e := os.ReadFile(filepath.Join(root, "go.mod"))
+		if e == nil {
+			module := modulePath(string(src))
+			if len(module) == 0 {
+				return
+			}
+			rel, _ := filepath.Rel(root, abs)
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return
+		}
+		root = parent
+	}
+}
+
+func modulePath(gomod string) string {
+	for _, line := range strings.Split(gomod, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t') {
+			rest = strings.TrimSpace(rest)
+			if unquoted, err := strconv.Unquote(rest); err == nil {
+				rest = unquoted
+			}
+			return rest
+		}
+	}
+	return ""
+}
+
+func importName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+func (g *gormLoader) parseFile(pkg *gormPackage, file *ast.File) {
+	var imports []gormImport
+	for _, spec := range file.Imports {
+		var imp gormImport
+		imp.path, _ = strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			imp.name = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != gotoken.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				t := &gormType{pkg: pkg, name: ts.Name.Name, expr: ts.Type, imports: imports}
+				doc := ts.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				if doc != nil {
+					t.doc = strings.TrimSpace(doc.Text())
+				}
+				pkg.types[t.name] = t
+			}
+		case *ast.FuncDecl:
+			if receiver, table, ok := tableNameMethod(decl); ok {
+				pkg.tables[receiver] = table
+			}
+		}
+	}
+}
+
+func tableNameMethod(decl *ast.FuncDecl) (receiver string, table string, ok bool) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil || len(decl.Body.List) != 1 {
+		return
+	}
+	expr := decl.Recv.List[0].Type
+	if star, isStar := expr.(*ast.StarExpr); isStar {
+		expr = star.X
+	}
+	ident, isIdent := expr.(*ast.Ident)
+	ret, isReturn := decl.Body.List[0].(*ast.ReturnStmt)
+	if !isIdent || !isReturn || len(ret.Results) != 1 {
+		return
+	}
+	lit, isLit := ret.Results[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != gotoken.STRING {
+		return
+	}
+	table, err := strconv.Unquote(lit.Value)
+	return ident.Name, table, err == nil
+}
+
+func (g *gormLoader) resolve(owner *gormType, expr ast.Expr) (name string, target *gormType, many bool) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return g.resolve(owner, e.X)
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && e.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "[]byte", nil, false
+		}
+		name, target, _ = g.resolve(owner, e.Elt)
+		return "[]" + name, target, e.Len == nil
+	case *ast.Ident:
+		if t, ok := owner.pkg.types[e.Name]; ok {
+			return g.named(t)
+		}
+		return e.Name, nil, false
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+		path := g.importPath(owner, x.Name)
+		pkgName := x.Name
+		if len(path) > 0 {
+			pkgName = importName(path)
+		}
+		name = pkgName + "." + e.Sel.Name
+		if path == "gorm.io/gorm" && e.Sel.Name == "Model" {
+			return "gorm.Model", g.model, false
+		}
+		if pkg, ok := g.byPath[path]; ok {
+			if t, ok := pkg.types[e.Sel.Name]; ok {
+				return g.named(t)
+			}
+			return
+		}
+		for _, pkg := range g.packages {
+			if t, ok := pkg.types[e.Sel.Name]; ok && len(pkg.path) == 0 && pkg.name == importName(path) {
+				return g.named(t)
+			}
+		}
+	}
+	return
+}
+
+func (g *gormLoader) importPath(owner *gormType, name string) string {
+	for _, imp := range owner.imports {
+		if imp.name == name {
+			return imp.path
+		}
+	}
+	for _, imp := range owner.imports {
+		if len(imp.name) > 0 {
+			continue
+		}
+		if pkg, ok := g.byPath[imp.path]; ok && pkg.name == name {
+			return imp.path
+		}
+		if _, ok := g.byPath[imp.path]; !ok && importName(imp.path) == name {
+			return imp.path
+		}
+	}
+	return ""
+}
+
+func (g *gormLoader) named(t *gormType) (name string, target *gormType, many bool) {
+	if _, ok := t.expr.(*ast.StructType); ok {
+		return t.name, t, false
+	}
+	return g.resolve(t, t.expr)
+}
+
+func (g *gormLoader) fields(t *gormType, prefix string) (fields []*gormField) {
+	st, ok := t.expr.(*ast.StructType)
+	if !ok {
+		return
+	}
+
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			s, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(s).Get("gorm")
+		}
+		settings := schema.ParseTagSetting(tag, ";")
+		if _, ok := settings["-"]; ok {
+			continue
+		}
+
+		if _, embedded := settings["EMBEDDED"]; embedded || len(field.Names) == 0 {
+			name, target, _ := g.resolve(t, field.Type)
+			if target == nil || target == t {
+				if len(field.Names) == 0 {
+					log.Printf("%s: skipping embedded %s", t.name, name)
+				}
+				continue
+			}
+			target.embedded = true
+			fields = append(fields, g.fields(target, prefix+settings["EMBEDDEDPREFIX"])...)
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			column := settings["COLUMN"]
+			if len(column) == 0 {
+				column = g.naming.ColumnName("", ident.Name)
+			}
+			fields = append(fields, &gormField{
+				name:     ident.Name,
+				column:   prefix + column,
+				expr:     field.Type,
+				owner:    t,
+				tag:      tag,
+				settings: settings,
+			})
+		}
+	}
+
+	return
+}
+
+func (t *gormType) hasPrimaryKey() bool {
+	for _, f := range t.fields {
+		if f.name == "ID" || gormTruth(f.settings["PRIMARYKEY"], f.settings["PRIMARY_KEY"]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *gormLoader) models() (models []*gormType) {
+	var types []*gormType
+	for _, pkg := range g.packages {
+		names := []string{}
+		for n, t := range pkg.types {
+			if _, ok := t.expr.(*ast.StructType); ok {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			types = append(types, pkg.types[n])
+		}
+	}
+
+	for _, t := range types {
+		t.fields = g.fields(t, "")
+	}
+	for _, t := range types {
+		if _, ok := t.pkg.tables[t.name]; ok {
+			t.model = true
+			continue
+		}
+		if t.embedded {
+			continue
+		}
+		for _, field := range t.expr.(*ast.StructType).Fields.List {
+			if field.Tag != nil && strings.Contains(field.Tag.Value, "gorm:") {
+				t.model = true
+			}
+			if _, target, _ := g.resolve(t, field.Type); len(field.Names) == 0 && target == g.model {
+				t.model = true
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, t := range types {
+			if !t.model {
+				continue
+			}
+			for _, f := range t.fields {
+				_, target, _ := g.resolve(f.owner, f.expr)
+				if target != nil && target != g.model && !target.model && !target.embedded && target.hasPrimaryKey() {
+					target.model = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, t := range types {
+		if t.model {
+			models = append(models, t)
+		}
+	}
+	return
+}
+
+func (g *gormLoader) table(t *gormType) {
+	table, ok := t.pkg.tables[t.name]
+	if !ok {
+		table = g.naming.TableName(t.name)
+	}
+	schemaName, n := g.p.qualify(strings.Split(table, "."))
+	info := &TableInfo{
+		Schema:  schemaName,
+		Name:    n,
+		Kind:    KindTable,
+		Columns: Columns{},
+		Comment: t.doc,
+	}
+	g.p.tables[tableKey(schemaName, n)] = info
+	t.table = table
+	t.info = info
+	t.columns = map[string]string{}
+
+	for _, f := range t.fields {
+		if gormTruth(f.settings["PRIMARYKEY"], f.settings["PRIMARY_KEY"]) {
+			t.primary = append(t.primary, f.name)
+		}
+	}
+	if len(t.primary) == 0 {
+		for _, f := range t.fields {
+			if f.name == "ID" {
+				t.primary = []string{f.name}
+			}
+		}
+	}
+
+	var indexes []*gormIndex
+	for _, f := range t.fields {
+		f.typeName, f.target, f.many = g.resolve(f.owner, f.expr)
+		if f.target != nil && f.target.model && len(f.settings["TYPE"]) == 0 && len(f.settings["SERIALIZER"]) == 0 {
+			continue
+		}
+		f.target = nil
+
+		primary := slices.Contains(t.primary, f.name)
+		_, hasDefault := f.settings["DEFAULT"]
+		_, hasAutoIncrement := f.settings["AUTOINCREMENT"]
+		autoIncrement := gormTruth(f.settings["AUTOINCREMENT"]) || (primary && len(t.primary) == 1 && !hasDefault && !hasAutoIncrement)
+
+		typ, err := f.sqlType(autoIncrement)
+		if err != nil {
+			log.Printf("%s.%s: skipping field: %v", t.name, f.name, err)
+			continue
+		}
+		spec, err := parseTypeSpec(typ)
+		if err != nil {
+			log.Printf("%s.%s: skipping field: %v", t.name, f.name, err)
+			continue
+		}
+
+		column := g.p.addColumn(info, f.column, spec)
+		t.columns[f.name] = f.column
+		if primary || gormTruth(f.settings["NOT NULL"], f.settings["NOTNULL"]) {
+			column.IsNullable = "NO"
+		}
+		if hasDefault && !spec.isSerial() {
+			column.ColumnDefault = f.defaultValue()
+		}
+		column.Comment = f.settings["COMMENT"]
+
+		if gormTruth(f.settings["UNIQUE"]) {
+			g.p.addConstraint(info, "", "u", []string{f.column}, "UNIQUE ("+f.column+")")
+		}
+		if check := f.settings["CHECK"]; len(check) > 0 {
+			name := g.naming.CheckerName(table, f.column)
+			if before, after, ok := strings.Cut(check, ","); ok && gormConstraintName.MatchString(before) {
+				name, check = before, after
+			} else if ok && len(before) == 0 {
+				check = after
+			}
+			g.p.addConstraint(info, name, "c", []string{f.column}, "CHECK ("+check+")")
+		}
+		indexes = g.fieldIndexes(t, f, indexes)
+	}
+
+	if len(t.primary) > 0 {
+		var columns []string
+		for _, name := range t.primary {
+			if column, ok := t.columns[name]; ok {
+				columns = append(columns, column)
+			}
+		}
+		g.p.addConstraint(info, "", "p", columns, "PRIMARY KEY ("+strings.Join(columns, ", ")+")")
+	}
+
+	for _, ix := range indexes {
+		sort.SliceStable(ix.columns, func(i, j int) bool {
+			return ix.priorities[i] < ix.priorities[j]
+		})
+		sort.SliceStable(ix.priorities, func(i, j int) bool {
+			return ix.priorities[i] < ix.priorities[j]
+		})
+		ix.index.Columns = ix.columns
+		g.p.indexes = append(g.p.indexes, &ddlIndex{tableKey(info.Schema, info.Name), ix.index})
+	}
+}
+
+func (g *gormLoader) fieldIndexes(t *gormType, f *gormField, indexes []*gormIndex) []*gormIndex {
+	for _, value := range strings.Split(f.tag, ";") {
+		v := strings.Split(value, ":")
+		k := strings.TrimSpace(strings.ToUpper(v[0]))
+		if k != "INDEX" && k != "UNIQUEINDEX" {
+			continue
+		}
+		name, options, _ := strings.Cut(strings.Join(v[1:], ":"), ",")
+		settings := schema.ParseTagSetting(options, ",")
+		if len(name) == 0 {
+			sub := f.name
+			if composite := settings["COMPOSITE"]; len(composite) > 0 {
+				sub = composite
+			}
+			name = g.naming.IndexName(t.table, sub)
+		}
+
+		var ix *gormIndex
+		for _, other := range indexes {
+			if other.index.Name == name {
+				ix = other
+			}
+		}
+		if ix == nil {
+			ix = &gormIndex{index: &Index{Name: name, Method: "btree"}}
+			indexes = append(indexes, ix)
+		}
+		if k == "UNIQUEINDEX" || len(settings["UNIQUE"]) > 0 || strings.EqualFold(settings["CLASS"], "UNIQUE") {
+			ix.index.IsUnique = true
+		}
+		if method := settings["TYPE"]; len(method) > 0 {
+			ix.index.Method = strings.ToLower(method)
+		}
+		if where := settings["WHERE"]; len(where) > 0 {
+			ix.index.Predicate = where
+		}
+		if comment := settings["COMMENT"]; len(comment) > 0 {
+			ix.index.Comment = comment
+		}
+
+		column := f.column
+		if expression := settings["EXPRESSION"]; len(expression) > 0 {
+			column = expression
+		}
+		priority, err := strconv.Atoi(settings["PRIORITY"])
+		if err != nil {
+			priority = 10
+		}
+		ix.columns = append(ix.columns, column)
+		ix.priorities = append(ix.priorities, priority)
+	}
+	return indexes
+}
+
+func splitTagList(s string) (list []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return
+}
+
+func (t *gormType) columnNames(fields []string) (columns []string, ok bool) {
+	for _, name := range fields {
+		column, found := t.columns[name]
+		if !found {
+			return nil, false
+		}
+		columns = append(columns, column)
+	}
+	return columns, len(columns) > 0
+}
+
+func (g *gormLoader) relations(models []*gormType) {
+	type relation struct {
+		owner    *gormType
+		field    *gormField
+		from, to *gormType
+		columns  []string
+		targets  []string
+	}
+
+	var has, belongs []relation
+	for _, t := range models {
+		for _, f := range t.fields {
+			if f.target == nil {
+				continue
+			}
+			if len(f.settings["POLYMORPHIC"]) > 0 {
+				log.Printf("%s.%s: skipping polymorphic association", t.name, f.name)
+				continue
+			}
+			if len(f.settings["MANY2MANY"]) > 0 {
+				g.joinTable(t, f)
+				continue
+			}
+
+			if !f.many {
+				references := splitTagList(f.settings["REFERENCES"])
+				if len(references) == 0 {
+					references = f.target.primary
+				}
+				foreignKeys := splitTagList(f.settings["FOREIGNKEY"])
+				if len(foreignKeys) == 0 {
+					for _, ref := range references {
+						foreignKeys = append(foreignKeys, f.name+ref)
+					}
+				}
+				columns, ok := t.columnNames(foreignKeys)
+				targets, found := f.target.columnNames(references)
+				if ok && found {
+					belongs = append(belongs, relation{t, f, t, f.target, columns, targets})
+					continue
+				}
+			}
+
+			references := splitTagList(f.settings["REFERENCES"])
+			if len(references) == 0 {
+				references = t.primary
+			}
+			foreignKeys := splitTagList(f.settings["FOREIGNKEY"])
+			if len(foreignKeys) == 0 {
+				for _, ref := range references {
+					foreignKeys = append(foreignKeys, t.name+ref)
+				}
+			}
+			columns, ok := f.target.columnNames(foreignKeys)
+			targets, found := t.columnNames(references)
+			if !ok || !found {
+				log.Printf("%s.%s: no foreign key found for association", t.name, f.name)
+				continue
+			}
+			has = append(has, relation{t, f, f.target, t, columns, targets})
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, rel := range append(has, belongs...) {
+		key := fmt.Sprint(rel.from.table, rel.columns, rel.to.table, rel.targets)
+		options := rel.field.settings["CONSTRAINT"]
+		if seen[key] || options == "-" {
+			continue
+		}
+		seen[key] = true
+
+		name := g.naming.RelationshipFKName(schema.Relationship{Name: rel.field.name, Schema: &schema.Schema{Table: rel.owner.table}})
+		if before, _, ok := strings.Cut(options, ","); ok && gormConstraintName.MatchString(before) {
+			name = before
+		}
+		g.addForeignKey(rel.from.info, name, rel.columns, rel.to.info, rel.targets, options)
+	}
+}
+
+func (g *gormLoader) joinTable(t *gormType, f *gormField) {
+	table := g.naming.JoinTableName(f.settings["MANY2MANY"])
+	schemaName, n := g.p.qualify(strings.Split(table, "."))
+	key := tableKey(schemaName, n)
+	if _, ok := g.p.tables[key]; ok {
+		return
+	}
+	info := &TableInfo{
+		Schema:  schemaName,
+		Name:    n,
+		Kind:    KindTable,
+		Columns: Columns{},
+	}
+	g.p.tables[key] = info
+
+	sides := []struct {
+		owner      *gormType
+		name       string
+		references string
+		joinKeys   string
+	}{
+		{t, t.name, f.settings["FOREIGNKEY"], f.settings["JOINFOREIGNKEY"]},
+		{f.target, f.target.name, f.settings["REFERENCES"], f.settings["JOINREFERENCES"]},
+	}
+	if f.target == t {
+		sides[1].name = inflection.Singular(f.name)
+	}
+
+	var primary []string
+	for _, side := range sides {
+		references := splitTagList(side.references)
+		if len(references) == 0 {
+			references = side.owner.primary
+		}
+		joinKeys := splitTagList(side.joinKeys)
+
+		var columns, targets []string
+		for i, ref := range references {
+			source, ok := side.owner.info.Columns[side.owner.columns[ref]]
+			if !ok {
+				continue
+			}
+			fieldName := side.name + ref
+			if i < len(joinKeys) {
+				fieldName = joinKeys[i]
+			}
+			spec := g.p.columnTypes[source]
+			if spec.isSerial() {
+				spec.words = []string{typeAliases[spec.words[0]]}
+			}
+			column := g.p.addColumn(info, g.naming.ColumnName("", fieldName), spec)
+			column.IsNullable = "NO"
+			columns = append(columns, column.ColumnName)
+			targets = append(targets, source.ColumnName)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		primary = append(primary, columns...)
+
+		name := g.naming.RelationshipFKName(schema.Relationship{Name: side.name, Schema: &schema.Schema{Table: table}})
+		g.addForeignKey(info, name, columns, side.owner.info, targets, f.settings["CONSTRAINT"])
+	}
+	g.p.addConstraint(info, "", "p", primary, "PRIMARY KEY ("+strings.Join(primary, ", ")+")")
+}
+
+func (g *gormLoader) addForeignKey(from *TableInfo, name string, columns []string, to *TableInfo, targets []string, options string) {
+	dc := g.p.addConstraint(from, name, "f", columns, "")
+	dc.constraint.TargetTableSchema = to.Schema
+	dc.constraint.TargetTableName = to.Name
+	dc.targets = targets
+
+	target := to.Name
+	if to.Schema != from.Schema {
+		target = to.Schema + "." + to.Name
+	}
+	definition := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)", strings.Join(columns, ", "), target, strings.Join(targets, ", "))
+	settings := schema.ParseTagSetting(options, ",")
+	if action := strings.ToUpper(settings["ONUPDATE"]); len(action) > 0 {
+		if code, ok := actionCodes[action]; ok {
+			dc.constraint.UpdateType = code
+			definition += " ON UPDATE " + action
+		}
+	}
+	if action := strings.ToUpper(settings["ONDELETE"]); len(action) > 0 {
+		if code, ok := actionCodes[action]; ok {
+			dc.constraint.DeleteType = code
+			definition += " ON DELETE " + action
+		}
+	}
+	dc.constraint.Definition = definition
+}
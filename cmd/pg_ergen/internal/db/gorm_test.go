@@ -0,0 +1,157 @@
+package db
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestGormSource(t *testing.T) {
+	source := &GormSource{Path: filepath.Join("testdata", "gormapp") + "/..."}
+	model, err := source.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Database != "gormapp" {
+		t.Errorf("Database = %q, want %q", model.Database, "gormapp")
+	}
+
+	tables := map[string]*TableInfo{}
+	var keys []string
+	for i := range model.Tables {
+		info := &model.Tables[i]
+		key := tableKey(info.Schema, info.Name)
+		tables[key] = info
+		keys = append(keys, key)
+	}
+	want := []string{
+		"i18n.languages", "legacy.customers", "public.customers", "public.invoices",
+		"public.posts", "public.profiles", "public.user_languages", "public.users",
+	}
+	if !slices.Equal(keys, want) {
+		t.Fatalf("tables = %v, want %v", keys, want)
+	}
+
+	users := tables["public.users"]
+	var columns []string
+	for _, c := range columnsByPosition(users) {
+		columns = append(columns, c.ColumnName)
+	}
+	wantColumns := []string{"id", "created_at", "updated_at", "deleted_at", "name", "age", "status", "customer_id", "audit_created_by", "audit_updated_at"}
+	if !slices.Equal(columns, wantColumns) {
+		t.Errorf("users columns = %v, want %v", columns, wantColumns)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"users primary key", users.PrimaryKey, []string{"id"}},
+		{"users comment", users.Comment, "User is an account."},
+		{"users.id type", users.Columns["id"].FormatType(nil), "bigint"},
+		{"users.id default", users.Columns["id"].ColumnDefault, "nextval('users_id_seq'::regclass)"},
+		{"users.name type", users.Columns["name"].FormatType(nil), "varchar(100)"},
+		{"users.name nullable", users.Columns["name"].IsNullable, "NO"},
+		{"users.name default", users.Columns["name"].ColumnDefault, "'anon'"},
+		{"users.name comment", users.Columns["name"].Comment, "display name"},
+		{"users.age type", users.Columns["age"].FormatType(nil), "smallint"},
+		{"users.deleted_at type", users.Columns["deleted_at"].FormatType(nil), "timestamptz"},
+		{"languages primary key", tables["i18n.languages"].PrimaryKey, []string{"code"}},
+		{"languages.code type", tables["i18n.languages"].Columns["code"].FormatType(nil), "varchar(8)"},
+		{"invoices.total type", tables["public.invoices"].Columns["total"].FormatType(nil), "numeric(12,2)"},
+		{"join table primary key", tables["public.user_languages"].PrimaryKey, []string{"user_id", "language_code"}},
+	}
+	for _, tt := range tests {
+		if !equalValues(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if len(users.Constraints) != 1 || users.Constraints[0].Name != "age_positive" || users.Constraints[0].Definition != "CHECK (age > 0)" {
+		t.Errorf("users constraints = %v, want age_positive", users.Constraints)
+	}
+	i := slices.IndexFunc(users.Indexes, func(ix *Index) bool { return ix.Name == "idx_status_customer" })
+	if i < 0 || !slices.Equal(users.Indexes[i].Columns, []string{"customer_id", "status"}) {
+		t.Errorf("users indexes = %v, want idx_status_customer on (customer_id, status)", users.Indexes)
+	}
+	if !slices.ContainsFunc(users.Indexes, func(ix *Index) bool { return ix.Name == "idx_users_deleted_at" }) {
+		t.Errorf("users indexes = %v, want idx_users_deleted_at from gorm.Model", users.Indexes)
+	}
+	if !slices.ContainsFunc(tables["public.customers"].UniqueKeys, func(key *UniqueKey) bool { return key.Name == "idx_customers_email" }) {
+		t.Errorf("customers unique keys = %v, want idx_customers_email", tables["public.customers"].UniqueKeys)
+	}
+
+	foreignKeys := []struct {
+		table   string
+		name    string
+		columns []string
+		target  string
+		refs    []string
+		update  string
+		delete  string
+	}{
+		{"public.users", "fk_users_customer", []string{"customer_id"}, "public.customers", []string{"id"}, "CASCADE", "SET NULL"},
+		{"public.profiles", "fk_users_profile", []string{"user_id"}, "public.users", []string{"id"}, "", ""},
+		{"public.posts", "fk_users_posts", []string{"author_id"}, "public.users", []string{"id"}, "", ""},
+		{"public.invoices", "fk_invoices_customer", []string{"customer_id"}, "public.customers", []string{"id"}, "", ""},
+		{"public.user_languages", "fk_user_languages_user", []string{"user_id"}, "public.users", []string{"id"}, "", ""},
+		{"public.user_languages", "fk_user_languages_language", []string{"language_code"}, "i18n.languages", []string{"code"}, "", ""},
+	}
+	for _, tt := range foreignKeys {
+		info := tables[tt.table]
+		i := slices.IndexFunc(info.ForeignKeys, func(fk *ForeignKey) bool { return fk.ConstraintName == tt.name })
+		if i < 0 {
+			t.Errorf("%s: missing foreign key %s in %v", tt.table, tt.name, info.ForeignKeys)
+			continue
+		}
+		fk := info.ForeignKeys[i]
+		if !slices.Equal(fk.Columns, tt.columns) || tableKey(fk.TableSchema, fk.TableName) != tt.target || !slices.Equal(fk.ReferencedColumns, tt.refs) {
+			t.Errorf("%s: %s(%v) → %s.%s(%v), want (%v) → %s(%v)", tt.table, tt.name, fk.Columns, fk.TableSchema, fk.TableName, fk.ReferencedColumns, tt.columns, tt.target, tt.refs)
+		}
+		if len(tt.update) > 0 && fk.UpdateRule != tt.update || len(tt.delete) > 0 && fk.DeleteRule != tt.delete {
+			t.Errorf("%s: %s ON UPDATE %s ON DELETE %s, want %s %s", tt.table, tt.name, fk.UpdateRule, fk.DeleteRule, tt.update, tt.delete)
+		}
+	}
+	for _, key := range []string{"legacy.customers", "public.customers", "i18n.languages"} {
+		if n := len(tables[key].ForeignKeys); n != 0 {
+			t.Errorf("%s has %d foreign keys, want none", key, n)
+		}
+	}
+}
+
+func TestGormTagSettings(t *testing.T) {
+	tests := []struct {
+		typ     string
+		tag     string
+		autoInc bool
+		want    string
+	}{
+		{"string", "", false, "text"},
+		{"string", "size:64", false, "varchar(64)"},
+		{"string", "type:citext", false, "citext"},
+		{"int", "", false, "bigint"},
+		{"int32", "", false, "integer"},
+		{"uint8", "", false, "smallint"},
+		{"uint", "", true, "bigserial"},
+		{"int32", "", true, "serial"},
+		{"float64", "precision:10;scale:2", false, "numeric(10, 2)"},
+		{"float32", "", false, "decimal"},
+		{"bool", "", false, "boolean"},
+		{"[]byte", "", false, "bytea"},
+		{"time.Time", "precision:3", false, "timestamptz(3)"},
+		{"sql.NullString", "", false, "text"},
+		{"uuid.UUID", "", false, "uuid"},
+	}
+	for _, tt := range tests {
+		f := &gormField{typeName: tt.typ, tag: tt.tag, settings: schema.ParseTagSetting(tt.tag, ";")}
+		got, err := f.sqlType(tt.autoInc)
+		if err != nil {
+			t.Errorf("%s `gorm:%q`: %v", tt.typ, tt.tag, err)
+		} else if got != tt.want {
+			t.Errorf("%s `gorm:%q` = %q, want %q", tt.typ, tt.tag, got, tt.want)
+		}
+	}
+}
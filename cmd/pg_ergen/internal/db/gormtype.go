@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var gormIntSizes = map[string]int{
+	"int":    64,
+	"int64":  64,
+	"uint":   64,
+	"uint64": 64,
+	"int32":  32,
+	"uint32": 32,
+	"rune":   32,
+	"int16":  16,
+	"uint16": 16,
+	"int8":   8,
+	"uint8":  8,
+	"byte":   8,
+}
+
+var gormNullTypes = map[string]string{
+	"sql.NullInt64": "int64",
+	"sql.NullInt32": "int32",
+	"sql.NullInt16": "int16",
+	"sql.NullByte":  "uint8",
+}
+
+var gormTypes = map[string]string{
+	"bool":              "boolean",
+	"string":            "text",
+	"float32":           "decimal",
+	"float64":           "decimal",
+	"[]byte":            "bytea",
+	"time.Time":         "timestamptz",
+	"sql.NullBool":      "boolean",
+	"sql.NullString":    "text",
+	"sql.NullFloat64":   "decimal",
+	"sql.NullTime":      "timestamptz",
+	"sql.RawBytes":      "bytea",
+	"uuid.UUID":         "uuid",
+	"decimal.Decimal":   "numeric",
+	"datatypes.JSON":    "jsonb",
+	"datatypes.JSONMap": "jsonb",
+	"datatypes.Date":    "date",
+	"datatypes.Time":    "time",
+	"pq.StringArray":    "text[]",
+	"pq.Int64Array":     "bigint[]",
+	"pq.Float64Array":   "double precision[]",
+	"pq.BoolArray":      "boolean[]",
+}
+
+func gormTruth(values ...string) bool {
+	for _, v := range values {
+		if len(v) > 0 && !strings.EqualFold(v, "false") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *gormField) sqlType(autoIncrement bool) (typ string, err error) {
+	if t := f.settings["TYPE"]; len(t) > 0 {
+		return t, nil
+	}
+	size, _ := strconv.Atoi(f.settings["SIZE"])
+	precision, _ := strconv.Atoi(f.settings["PRECISION"])
+	scale, _ := strconv.Atoi(f.settings["SCALE"])
+
+	name := f.typeName
+	if n, ok := gormNullTypes[name]; ok {
+		name = n
+	}
+	if bits, ok := gormIntSizes[name]; ok {
+		if size > 0 {
+			bits = size
+		}
+		if strings.HasPrefix(name, "uint") || name == "byte" {
+			bits += 1
+		}
+		switch {
+		case bits <= 16:
+			typ = "smallint"
+		case bits <= 32:
+			typ = "integer"
+		default:
+			typ = "bigint"
+		}
+		if autoIncrement {
+			typ = map[string]string{"smallint": "smallserial", "integer": "serial", "bigint": "bigserial"}[typ]
+		}
+		return
+	}
+
+	typ, ok := gormTypes[name]
+	if !ok {
+		err = fmt.Errorf("no column type for %s (add a type: tag)", name)
+		return
+	}
+	switch {
+	case typ == "text" && size > 0:
+		typ = fmt.Sprintf("varchar(%d)", size)
+	case typ == "decimal" && precision > 0 && scale > 0:
+		typ = fmt.Sprintf("numeric(%d, %d)", precision, scale)
+	case typ == "decimal" && precision > 0:
+		typ = fmt.Sprintf("numeric(%d)", precision)
+	case typ == "timestamptz" && precision > 0:
+		typ = fmt.Sprintf("timestamptz(%d)", precision)
+	}
+
+	return
+}
+
+func (f *gormField) defaultValue() string {
+	value := f.settings["DEFAULT"]
+	if f.typeName != "string" || strings.Contains(value, "(") {
+		return value
+	}
+	return "'" + strings.Trim(strings.Trim(value, "'"), `"`) + "'"
+}
+
+func parseTypeSpec(s string) (spec typeSpec, err error) {
+	statements, err := splitStatements(s)
+	if err != nil {
+		return
+	}
+	if len(statements) != 1 {
+		err = fmt.Errorf("invalid type %q", s)
+		return
+	}
+	st := statements[0]
+	c := &cursor{st, 0, len(st.tokens)}
+	spec, err = c.typeSpec()
+	if err == nil && !c.done() {
+		err = c.errorf("invalid type %q", s)
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package db
+
+type Index struct {
+	Name      string
+	Columns   []string
+	IsUnique  bool
+	IsPrimary bool
+	Predicate string
+	Method    string
+	Comment   string
+}
+
+type index struct {
+	TableSchema string
+	TableName   string
+	IndexName   string
+	ColumnNames string
+	IsUnique    bool
+	IsPrimary   bool
+	Predicate   string
+	Method      string
+	Comment     string
+}
+
+func (c *DBConnect) indexes(schemas []string, tables tableSet) (err error) {
+	sql := `
+	SELECT
+		N.nspname table_schema,
+		C.relname table_name,
+		IC.relname index_name,
+		(
+			SELECT array_to_json(array_agg(COALESCE(A.attname, pg_get_indexdef(I.indexrelid, K.n::int, true)) ORDER BY K.n))::text
+			FROM unnest(I.indkey::int2[]) WITH ORDINALITY K(attnum, n)
+				LEFT JOIN pg_attribute A ON A.attrelid = I.indrelid AND A.attnum = K.attnum
+			WHERE K.n <= I.indnkeyatts
+		) column_names,
+		I.indisunique is_unique,
+		I.indisprimary is_primary,
+		pg_get_expr(I.indpred, I.indrelid, true) predicate,
+		AM.amname method,
+		COALESCE(obj_description(I.indexrelid, 'pg_class'), '') comment
+	FROM
+		pg_index I
+		INNER JOIN pg_class C ON C.oid = I.indrelid
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		INNER JOIN pg_class IC ON IC.oid = I.indexrelid
+		INNER JOIN pg_am AM ON AM.oid = IC.relam
+	WHERE
+		N.nspname IN ?
+	ORDER BY
+		N.nspname,
+		C.relname,
+		IC.relname
+	`
+	rows, err := c.db.Raw(sql, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var ix index
+		c.db.ScanRows(rows, &ix)
+		info, ok := tables[tableKey(ix.TableSchema, ix.TableName)]
+		if !ok {
+			continue
+		}
+		info.Indexes = append(info.Indexes, &Index{
+			Name:      ix.IndexName,
+			Columns:   decodeNames(ix.ColumnNames),
+			IsUnique:  ix.IsUnique,
+			IsPrimary: ix.IsPrimary,
+			Predicate: ix.Predicate,
+			Method:    ix.Method,
+			Comment:   ix.Comment,
+		})
+	}
+
+	return
+}
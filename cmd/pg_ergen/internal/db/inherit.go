@@ -0,0 +1,74 @@
+package db
+
+func (c *DBConnect) inherits(schemas []string, tables tableSet) (err error) {
+	sql := `
+	SELECT
+		N.nspname,
+		C.relname,
+		PN.nspname,
+		P.relname
+	FROM
+		pg_inherits I
+		INNER JOIN pg_class C ON C.oid = I.inhrelid
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		INNER JOIN pg_class P ON P.oid = I.inhparent
+		INNER JOIN pg_namespace PN ON PN.oid = P.relnamespace
+	WHERE
+		N.nspname IN ?
+		AND NOT C.relispartition
+	ORDER BY
+		1, 2, I.inhseqno
+	`
+	rows, err := c.db.Raw(sql, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, n string
+		var tn TableName
+		rows.Scan(&schema, &n, &tn.Schema, &tn.Name)
+		if info, ok := tables[tableKey(schema, n)]; ok {
+			info.Inherits = append(info.Inherits, tn)
+		}
+	}
+
+	sql = `
+	SELECT
+		N.nspname,
+		C.relname,
+		A.attname
+	FROM
+		pg_attribute A
+		INNER JOIN pg_class C ON C.oid = A.attrelid
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+	WHERE
+		N.nspname IN ?
+		AND NOT C.relispartition
+		AND A.attnum > 0
+		AND NOT A.attisdropped
+		AND A.attinhcount > 0
+	`
+	rows, err = c.db.Raw(sql, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, n, name string
+		rows.Scan(&schema, &n, &name)
+		info, ok := tables[tableKey(schema, n)]
+		if !ok || len(info.Inherits) == 0 {
+			continue
+		}
+		if column, ok := info.Columns[name]; ok {
+			column.IsInherited = true
+		}
+	}
+
+	return
+}
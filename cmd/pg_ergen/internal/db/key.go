@@ -0,0 +1,59 @@
+package db
+
+import "slices"
+
+type UniqueKey struct {
+	Name    string
+	Columns []string
+	IsIndex bool
+	Comment string
+}
+
+func (info *TableInfo) addUniqueKey(key *UniqueKey) {
+	info.UniqueKeys = append(info.UniqueKeys, key)
+	if len(key.Columns) == 1 {
+		if column, ok := info.Columns[key.Columns[0]]; ok {
+			column.IsUnique = true
+		}
+	}
+}
+
+func (info *TableInfo) addUniqueIndexes() {
+	for _, ix := range info.Indexes {
+		if !ix.IsUnique || ix.IsPrimary || len(ix.Predicate) > 0 {
+			continue
+		}
+		if slices.ContainsFunc(info.UniqueKeys, func(key *UniqueKey) bool { return key.Name == ix.Name }) {
+			continue
+		}
+		info.addUniqueKey(&UniqueKey{
+			Name:    ix.Name,
+			Columns: ix.Columns,
+			IsIndex: true,
+			Comment: ix.Comment,
+		})
+	}
+}
+
+func (info *TableInfo) IsUnique(columns []string) bool {
+	keys := [][]string{info.PrimaryKey}
+	for _, key := range info.UniqueKeys {
+		keys = append(keys, key.Columns)
+	}
+	for _, key := range keys {
+		if len(key) == 0 {
+			continue
+		}
+		covered := true
+		for _, name := range key {
+			if !slices.Contains(columns, name) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package db
+
+func (c *DBConnect) partitions(schemas []string, tables tableSet) (err error) {
+	sql := `
+	SELECT
+		N.nspname,
+		C.relname,
+		COALESCE(pg_get_partkeydef(C.oid), ''),
+		COALESCE(pg_get_expr(C.relpartbound, C.oid, true), ''),
+		COALESCE(PN.nspname, ''),
+		COALESCE(P.relname, '')
+	FROM
+		pg_class C
+		INNER JOIN pg_namespace N ON N.oid = C.relnamespace
+		LEFT JOIN pg_inherits I ON I.inhrelid = C.oid AND C.relispartition
+		LEFT JOIN pg_class P ON P.oid = I.inhparent
+		LEFT JOIN pg_namespace PN ON PN.oid = P.relnamespace
+	WHERE
+		(N.nspname IN ? OR PN.nspname IN ?)
+		AND (C.relkind = 'p' OR C.relispartition)
+	ORDER BY
+		1, 2
+	`
+	rows, err := c.db.Raw(sql, schemas, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	children := map[string][]TableName{}
+	for rows.Next() {
+		var schema, n, key, bound string
+		var parent TableName
+		rows.Scan(&schema, &n, &key, &bound, &parent.Schema, &parent.Name)
+		if len(parent.Name) > 0 {
+			pk := tableKey(parent.Schema, parent.Name)
+			children[pk] = append(children[pk], TableName{Schema: schema, Name: n, IsPartition: true})
+		}
+
+		info, ok := tables[tableKey(schema, n)]
+		if !ok {
+			continue
+		}
+		info.PartitionKey = key
+		info.PartitionBound = bound
+		if len(parent.Name) > 0 {
+			info.PartitionOf = &parent
+		}
+	}
+
+	for k, info := range tables {
+		if len(info.PartitionKey) > 0 {
+			info.Partitions = children[k]
+		}
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+)
+
+type Model struct {
+	Database   string
+	Tables     []TableInfo
+	Types      *UserTypes
+	SnapshotAt time.Time
+	WalLSN     string
+}
+
+func (c *DBConnect) Snapshot() (model Model, err error) {
+	if c.db == nil {
+		_, err = c.Connect()
+		if err != nil {
+			return
+		}
+	}
+
+	conn := c.db
+	tx := conn.Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if tx.Error != nil {
+		err = tx.Error
+		return
+	}
+	c.db = tx
+	c.types = nil
+	defer func() {
+		tx.Rollback()
+		c.db = conn
+	}()
+
+	if len(c.StatementTimeout) > 0 {
+		err = tx.Exec("SELECT set_config('statement_timeout', ?, true)", c.StatementTimeout).Error
+		if err != nil {
+			return
+		}
+	}
+
+	sql := `
+	SELECT
+		now(),
+		CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END::text
+	`
+	err = tx.Raw(sql).Row().Scan(&model.SnapshotAt, &model.WalLSN)
+	if err != nil {
+		return
+	}
+
+	names, err := c.Tablenames()
+	if err != nil {
+		return
+	}
+
+	model.Tables, err = c.GetTableInfos(names)
+	if err != nil {
+		return
+	}
+
+	model.Database = c.Dbname
+	model.Types, err = c.UserTypes()
+
+	return
+}
+
+func (m *Model) FilterSchemas(schemas []string, exclude []string) {
+	m.Tables = slices.DeleteFunc(m.Tables, func(info TableInfo) bool {
+		return !selectSchema(schemas, exclude, info.Schema)
+	})
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const SnapshotVersion = 1
+
+type snapshotFile struct {
+	Version int
+	Model
+}
+
+func (m *Model) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(snapshotFile{SnapshotVersion, *m})
+}
+
+func ReadModel(r io.Reader) (model Model, err error) {
+	var file snapshotFile
+	err = json.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return
+	}
+	if file.Version != SnapshotVersion {
+		err = fmt.Errorf("unsupported snapshot version %d (expected %d)", file.Version, SnapshotVersion)
+		return
+	}
+
+	model = file.Model
+	model.link()
+
+	return
+}
+
+func (m *Model) link() {
+	if m.Types == nil {
+		m.Types = &UserTypes{}
+	}
+	for i := range m.Tables {
+		info := &m.Tables[i]
+		if info.Columns == nil {
+			info.Columns = Columns{}
+		}
+		m.Types.resolve(info.Columns)
+		for _, fk := range info.ForeignKeys {
+			for _, name := range fk.Columns {
+				if column, ok := info.Columns[name]; ok {
+					column.ForeignKeys = append(column.ForeignKeys, fk)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package db
+
+import "os"
+
+type SchemaSource interface {
+	Snapshot() (Model, error)
+}
+
+type SnapshotFile struct {
+	Path string
+}
+
+func (s *SnapshotFile) Snapshot() (model Model, err error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	return ReadModel(f)
+}
@@ -0,0 +1,263 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+const sqliteSchema = "main"
+
+type SQLiteSource struct {
+	Path string
+
+	db *gorm.DB
+}
+
+func (s *SQLiteSource) Snapshot() (model Model, err error) {
+	s.db, err = gorm.Open(sqlite.Open("file:"+s.Path+"?mode=ro"), &gorm.Config{})
+	if err != nil {
+		return
+	}
+	if conn, e := s.db.DB(); e == nil {
+		defer conn.Close()
+	}
+
+	model.Database = strings.TrimSuffix(filepath.Base(s.Path), filepath.Ext(s.Path))
+	model.Types = &UserTypes{Enums: map[string]*Enum{}, Domains: map[string]*Domain{}}
+	model.SnapshotAt = time.Now()
+
+	sql := `
+	SELECT
+		name,
+		type
+	FROM
+		sqlite_master
+	WHERE
+		type IN ('table', 'view')
+		AND name NOT LIKE 'sqlite_%'
+	ORDER BY
+		name
+	`
+	rows, err := s.db.Raw(sql).Rows()
+	if err != nil {
+		return
+	}
+
+	for rows.Next() {
+		var n, kind string
+		rows.Scan(&n, &kind)
+		info := TableInfo{
+			Schema:  sqliteSchema,
+			Name:    n,
+			Kind:    KindTable,
+			Columns: Columns{},
+		}
+		if kind == "view" {
+			info.Kind = KindView
+		}
+		model.Tables = append(model.Tables, info)
+	}
+	rows.Close()
+
+	for i := range model.Tables {
+		info := &model.Tables[i]
+		err = s.columns(info)
+		if err != nil {
+			return
+		}
+		err = s.indexes(info)
+		if err != nil {
+			return
+		}
+	}
+
+	primaryKeys := map[string][]string{}
+	for _, info := range model.Tables {
+		primaryKeys[info.Name] = info.PrimaryKey
+	}
+	for i := range model.Tables {
+		err = s.foreignKeys(&model.Tables[i], primaryKeys)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (s *SQLiteSource) columns(info *TableInfo) (err error) {
+	sql := `
+	SELECT
+		cid,
+		name,
+		type,
+		"notnull",
+		COALESCE(dflt_value, ''),
+		pk
+	FROM
+		pragma_table_xinfo(?)
+	WHERE
+		hidden IN (0, 2, 3)
+	ORDER BY
+		cid
+	`
+	rows, err := s.db.Raw(sql, info.Name).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	keys := map[int]string{}
+	for rows.Next() {
+		var cid, pk int
+		var notNull bool
+		var n, dataType, dflt string
+		rows.Scan(&cid, &n, &dataType, &notNull, &dflt, &pk)
+
+		column := &Column{
+			TableSchema:     sqliteSchema,
+			TableName:       info.Name,
+			ColumnName:      n,
+			OrdinalPosition: cid + 1,
+			ColumnDefault:   dflt,
+			IsNullable:      "YES",
+			DataType:        strings.ToLower(dataType),
+			IsPrimaryKey:    pk > 0,
+		}
+		if notNull || pk > 0 {
+			column.IsNullable = "NO"
+		}
+		if pk > 0 {
+			keys[pk] = n
+		}
+		info.Columns[n] = column
+	}
+
+	for k := 1; k <= len(keys); k += 1 {
+		info.PrimaryKey = append(info.PrimaryKey, keys[k])
+	}
+
+	return
+}
+
+func (s *SQLiteSource) indexes(info *TableInfo) (err error) {
+	sql := `
+	SELECT
+		L.name,
+		L."unique",
+		L.origin,
+		L.partial,
+		COALESCE((SELECT sql FROM sqlite_master WHERE type = 'index' AND name = L.name), ''),
+		(
+			SELECT json_group_array(COALESCE(I.name, ''))
+			FROM (SELECT name FROM pragma_index_info(L.name) ORDER BY seqno) I
+		)
+	FROM
+		pragma_index_list(?) L
+	ORDER BY
+		L.name
+	`
+	rows, err := s.db.Raw(sql, info.Name).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var n, origin, definition, columns string
+		var unique, partial bool
+		rows.Scan(&n, &unique, &origin, &partial, &definition, &columns)
+
+		ix := &Index{
+			Name:      n,
+			Columns:   decodeNames(columns),
+			IsUnique:  unique,
+			IsPrimary: origin == "pk",
+			Method:    "btree",
+		}
+		if partial {
+			if i := strings.LastIndex(strings.ToUpper(definition), " WHERE "); i >= 0 {
+				ix.Predicate = strings.TrimSpace(definition[i+7:])
+			}
+		}
+		info.Indexes = append(info.Indexes, ix)
+		if origin == "u" {
+			info.addUniqueKey(&UniqueKey{
+				Name:    n,
+				Columns: ix.Columns,
+			})
+		}
+	}
+	info.addUniqueIndexes()
+
+	return
+}
+
+func (s *SQLiteSource) foreignKeys(info *TableInfo, primaryKeys map[string][]string) (err error) {
+	sql := `
+	SELECT
+		id,
+		"table",
+		"from",
+		COALESCE("to", ''),
+		on_update,
+		on_delete,
+		"match"
+	FROM
+		pragma_foreign_key_list(?)
+	ORDER BY
+		id,
+		seq
+	`
+	rows, err := s.db.Raw(sql, info.Name).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	keys := map[int]*ForeignKey{}
+	for rows.Next() {
+		var id int
+		var target, from, to, onUpdate, onDelete, match string
+		rows.Scan(&id, &target, &from, &to, &onUpdate, &onDelete, &match)
+
+		fk, ok := keys[id]
+		if !ok {
+			fk = &ForeignKey{
+				ConstraintName: fmt.Sprintf("fk_%s_%d", info.Name, id),
+				TableSchema:    sqliteSchema,
+				TableName:      target,
+				MatchOption:    match,
+				UpdateRule:     onUpdate,
+				DeleteRule:     onDelete,
+			}
+			keys[id] = fk
+			info.ForeignKeys = append(info.ForeignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, from)
+		if len(to) > 0 {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, to)
+		}
+	}
+
+	for _, fk := range info.ForeignKeys {
+		if len(fk.ReferencedColumns) == 0 {
+			fk.ReferencedColumns = primaryKeys[fk.TableName]
+		}
+		for _, name := range fk.Columns {
+			if column, ok := info.Columns[name]; ok {
+				column.ForeignKeys = append(column.ForeignKeys, fk)
+			}
+		}
+	}
+
+	return
+}
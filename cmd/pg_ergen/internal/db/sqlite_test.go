@@ -0,0 +1,132 @@
+package db
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+const sqliteFixture = `
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY,
+	email TEXT NOT NULL UNIQUE,
+	name TEXT DEFAULT 'anon'
+);
+CREATE TABLE orders (
+	id INTEGER NOT NULL,
+	line INTEGER NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES users ON DELETE CASCADE,
+	state TEXT,
+	PRIMARY KEY (id, line)
+);
+CREATE INDEX orders_state_idx ON orders (state) WHERE state <> 'paid';
+CREATE UNIQUE INDEX orders_user_state ON orders (user_id, state);
+CREATE VIEW active_users AS SELECT id, email FROM users;
+`
+
+func sqliteModel(t *testing.T) (tables map[string]*TableInfo) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.db")
+
+	conn, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.Exec(sqliteFixture).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sqlDB, err := conn.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	source := &SQLiteSource{Path: path}
+	model, err := source.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Database != "fixture" {
+		t.Errorf("Database = %q, want %q", model.Database, "fixture")
+	}
+
+	tables = map[string]*TableInfo{}
+	for i := range model.Tables {
+		tables[model.Tables[i].Name] = &model.Tables[i]
+	}
+	return
+}
+
+func TestSQLiteSource(t *testing.T) {
+	tables := sqliteModel(t)
+	if len(tables) != 3 {
+		t.Fatalf("got %d tables, want 3", len(tables))
+	}
+
+	users := tables["users"]
+	orders := tables["orders"]
+	view := tables["active_users"]
+	if users == nil || orders == nil || view == nil {
+		t.Fatalf("missing tables: %v", tables)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"users kind", users.Kind, KindTable},
+		{"view kind", view.Kind, KindView},
+		{"users primary key", users.PrimaryKey, []string{"id"}},
+		{"orders primary key", orders.PrimaryKey, []string{"id", "line"}},
+		{"email nullable", users.Columns["email"].IsNullable, "NO"},
+		{"name nullable", users.Columns["name"].IsNullable, "YES"},
+		{"name default", users.Columns["name"].ColumnDefault, "'anon'"},
+		{"name position", users.Columns["name"].OrdinalPosition, 3},
+		{"id is primary key", users.Columns["id"].IsPrimaryKey, true},
+		{"view columns", len(view.Columns), 2},
+	}
+	for _, tt := range tests {
+		if !equalValues(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if len(orders.ForeignKeys) != 1 {
+		t.Fatalf("orders has %d foreign keys, want 1", len(orders.ForeignKeys))
+	}
+	fk := orders.ForeignKeys[0]
+	if fk.TableName != "users" || !slices.Equal(fk.Columns, []string{"user_id"}) || !slices.Equal(fk.ReferencedColumns, []string{"id"}) {
+		t.Errorf("foreign key = %s(%v) → %s(%v)", fk.ConstraintName, fk.Columns, fk.TableName, fk.ReferencedColumns)
+	}
+	if fk.DeleteRule != "CASCADE" {
+		t.Errorf("DeleteRule = %q, want CASCADE", fk.DeleteRule)
+	}
+	if !slices.Contains(orders.Columns["user_id"].ForeignKeys, fk) {
+		t.Error("user_id column is not linked to its foreign key")
+	}
+
+	if !slices.ContainsFunc(users.UniqueKeys, func(key *UniqueKey) bool { return slices.Equal(key.Columns, []string{"email"}) && !key.IsIndex }) {
+		t.Errorf("users unique keys = %v, want a constraint on email", users.UniqueKeys)
+	}
+	if !slices.ContainsFunc(orders.UniqueKeys, func(key *UniqueKey) bool { return key.Name == "orders_user_state" && key.IsIndex }) {
+		t.Errorf("orders unique keys = %v, want the orders_user_state index", orders.UniqueKeys)
+	}
+
+	i := slices.IndexFunc(orders.Indexes, func(ix *Index) bool { return ix.Name == "orders_state_idx" })
+	if i < 0 {
+		t.Fatalf("orders indexes = %v, want orders_state_idx", orders.Indexes)
+	}
+	if ix := orders.Indexes[i]; ix.IsUnique || !slices.Equal(ix.Columns, []string{"state"}) || ix.Predicate != "state <> 'paid'" {
+		t.Errorf("orders_state_idx = %+v", ix)
+	}
+}
+
+func equalValues(got any, want any) bool {
+	if g, ok := got.([]string); ok {
+		w, _ := want.([]string)
+		return slices.Equal(g, w)
+	}
+	return got == want
+}
@@ -0,0 +1,8 @@
+package models
+
+type Customer struct {
+	ID   uint
+	Code string
+}
+
+func (Customer) TableName() string { return "legacy.customers" }
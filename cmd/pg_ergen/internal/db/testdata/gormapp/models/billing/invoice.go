@@ -0,0 +1,14 @@
+package billing
+
+import (
+	"github.com/shopspring/decimal"
+
+	"example.com/shop/models"
+)
+
+type Invoice struct {
+	ID         uint
+	CustomerID uint
+	Customer   models.Customer
+	Total      decimal.Decimal `gorm:"type:numeric(12,2)"`
+}
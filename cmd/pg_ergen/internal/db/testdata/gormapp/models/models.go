@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Customer struct {
+	ID    uint   `gorm:"primaryKey"`
+	Email string `gorm:"size:320;not null;uniqueIndex"`
+}
+
+type Audit struct {
+	CreatedBy string
+	UpdatedAt time.Time
+}
+
+// User is an account.
+type User struct {
+	gorm.Model
+	Name       string   `gorm:"size:100;not null;default:anon;comment:display name"`
+	Age        uint8    `gorm:"check:age_positive,age > 0"`
+	Status     string   `gorm:"index:idx_status_customer,priority:2"`
+	CustomerID uint     `gorm:"index:idx_status_customer,priority:1"`
+	Customer   Customer `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Profile    Profile
+	Posts      []Post     `gorm:"foreignKey:AuthorID"`
+	Languages  []Language `gorm:"many2many:user_languages;"`
+	Audit      Audit      `gorm:"embedded;embeddedPrefix:audit_"`
+	Secret     string     `gorm:"-"`
+	internal   string
+}
+
+type Profile struct {
+	ID     uint
+	UserID uint
+	Bio    string
+}
+
+type Post struct {
+	ID       uint
+	AuthorID uint
+	Title    string `gorm:"size:200"`
+}
+
+type Language struct {
+	Code string `gorm:"primaryKey;size:8"`
+	Name string
+}
+
+func (Language) TableName() string { return "i18n.languages" }
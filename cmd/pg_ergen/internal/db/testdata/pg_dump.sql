@@ -0,0 +1,287 @@
+--
+-- PostgreSQL database dump
+--
+
+-- Dumped from database version 16.2
+-- Dumped by pg_dump version 16.2
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+
+--
+-- Name: billing; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA billing;
+
+
+ALTER SCHEMA billing OWNER TO postgres;
+
+--
+-- Name: order_state; Type: TYPE; Schema: public; Owner: postgres
+--
+
+CREATE TYPE public.order_state AS ENUM (
+    'new',
+    'paid',
+    'it''s shipped'
+);
+
+
+ALTER TYPE public.order_state OWNER TO postgres;
+
+--
+-- Name: email; Type: DOMAIN; Schema: public; Owner: postgres
+--
+
+CREATE DOMAIN public.email AS character varying(320)
+	CONSTRAINT email_check CHECK (((VALUE)::text ~ '@'::text));
+
+
+ALTER DOMAIN public.email OWNER TO postgres;
+
+--
+-- Name: touch(); Type: FUNCTION; Schema: public; Owner: postgres
+--
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.updated_at := now(); -- keep; this
+    RETURN NEW;
+END;
+$$;
+
+
+ALTER FUNCTION public.touch() OWNER TO postgres;
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: audited; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.audited (
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone
+);
+
+
+ALTER TABLE public.audited OWNER TO postgres;
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.users (
+    id bigint NOT NULL,
+    email public.email NOT NULL,
+    name character varying(80) DEFAULT 'anon'::character varying,
+    tags character varying(20)[],
+    "Weird Col" integer
+)
+INHERITS (public.audited);
+
+
+ALTER TABLE public.users OWNER TO postgres;
+
+--
+-- Name: TABLE users; Type: COMMENT; Schema: public; Owner: postgres
+--
+
+COMMENT ON TABLE public.users IS 'Registered users';
+
+
+--
+-- Name: COLUMN users.name; Type: COMMENT; Schema: public; Owner: postgres
+--
+
+COMMENT ON COLUMN public.users.name IS 'Display name';
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.users_id_seq OWNER TO postgres;
+
+--
+-- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: postgres
+--
+
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+
+--
+-- Name: orders; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    user_id bigint NOT NULL,
+    state public.order_state DEFAULT 'new'::public.order_state NOT NULL,
+    amount numeric(12,2),
+    placed_at timestamp with time zone NOT NULL,
+    CONSTRAINT amount_positive CHECK ((amount > (0)::numeric))
+)
+PARTITION BY RANGE (placed_at);
+
+
+ALTER TABLE public.orders OWNER TO postgres;
+
+--
+-- Name: orders_2024; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.orders_2024 (
+    id integer NOT NULL,
+    user_id bigint NOT NULL,
+    state public.order_state DEFAULT 'new'::public.order_state NOT NULL,
+    amount numeric(12,2),
+    placed_at timestamp with time zone NOT NULL,
+    CONSTRAINT amount_positive CHECK ((amount > (0)::numeric))
+);
+
+
+ALTER TABLE public.orders_2024 OWNER TO postgres;
+
+--
+-- Name: invoices; Type: TABLE; Schema: billing; Owner: postgres
+--
+
+CREATE TABLE billing.invoices (
+    id integer NOT NULL,
+    order_id integer,
+    placed_at timestamp with time zone,
+    total numeric
+);
+
+
+ALTER TABLE billing.invoices OWNER TO postgres;
+
+ALTER TABLE billing.invoices ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME billing.invoices_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: orders_2024; Type: TABLE ATTACH; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.orders ATTACH PARTITION public.orders_2024 FOR VALUES FROM ('2024-01-01 00:00:00+00') TO ('2025-01-01 00:00:00+00');
+
+
+--
+-- Name: users id; Type: DEFAULT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+
+
+--
+-- Name: invoices invoices_pkey; Type: CONSTRAINT; Schema: billing; Owner: postgres
+--
+
+ALTER TABLE ONLY billing.invoices
+    ADD CONSTRAINT invoices_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: orders orders_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id, placed_at);
+
+
+--
+-- Name: users users_email_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: orders_state_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX orders_state_idx ON ONLY public.orders USING hash (state) WHERE (state <> 'paid'::public.order_state);
+
+
+--
+-- Name: users_lower_name_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX users_lower_name_idx ON public.users USING btree (lower((name)::text));
+
+
+--
+-- Name: INDEX users_lower_name_idx; Type: COMMENT; Schema: public; Owner: postgres
+--
+
+COMMENT ON INDEX public.users_lower_name_idx IS 'case-insensitive names';
+
+
+--
+-- Name: users touch; Type: TRIGGER; Schema: public; Owner: postgres
+--
+
+CREATE TRIGGER touch BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION public.touch();
+
+
+--
+-- Name: invoices invoices_order_fkey; Type: FK CONSTRAINT; Schema: billing; Owner: postgres
+--
+
+ALTER TABLE billing.invoices
+    ADD CONSTRAINT invoices_order_fkey FOREIGN KEY (order_id, placed_at) REFERENCES public.orders(id, placed_at) ON DELETE SET NULL;
+
+
+--
+-- Name: orders orders_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED;
+
+
+--
+-- Name: CONSTRAINT orders_user_id_fkey ON orders; Type: COMMENT; Schema: public; Owner: postgres
+--
+
+COMMENT ON CONSTRAINT orders_user_id_fkey ON public.orders IS 'owner';
+
+
+--
+-- PostgreSQL database dump complete
+--
+
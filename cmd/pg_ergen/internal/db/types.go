@@ -0,0 +1,112 @@
+package db
+
+type Enum struct {
+	Schema  string
+	Name    string
+	Labels  []string
+	Comment string
+}
+
+type Domain struct {
+	Schema      string
+	Name        string
+	BaseType    string
+	NotNull     bool
+	Default     string
+	Constraints []string
+	Comment     string
+}
+
+type UserTypes struct {
+	Enums   map[string]*Enum
+	Domains map[string]*Domain
+}
+
+func (c *DBConnect) UserTypes() (types *UserTypes, err error) {
+	if c.types != nil {
+		return c.types, nil
+	}
+
+	types = &UserTypes{
+		Enums:   map[string]*Enum{},
+		Domains: map[string]*Domain{},
+	}
+
+	sql := `
+	SELECT
+		N.nspname,
+		T.typname,
+		array_to_json(array_agg(E.enumlabel ORDER BY E.enumsortorder))::text,
+		COALESCE(obj_description(T.oid, 'pg_type'), '')
+	FROM
+		pg_type T
+		INNER JOIN pg_namespace N ON N.oid = T.typnamespace
+		INNER JOIN pg_enum E ON E.enumtypid = T.oid
+	GROUP BY
+		T.oid,
+		N.nspname,
+		T.typname
+	`
+	rows, err := c.db.Raw(sql).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var labels string
+		enum := &Enum{}
+		rows.Scan(&enum.Schema, &enum.Name, &labels, &enum.Comment)
+		enum.Labels = decodeNames(labels)
+		types.Enums[enum.Schema+"."+enum.Name] = enum
+	}
+
+	sql = `
+	SELECT
+		N.nspname,
+		T.typname,
+		format_type(T.typbasetype, T.typtypmod),
+		T.typnotnull,
+		COALESCE(T.typdefault, ''),
+		COALESCE((
+			SELECT array_to_json(array_agg(pg_get_constraintdef(C.oid, true) ORDER BY C.conname))::text
+			FROM pg_constraint C
+			WHERE C.contypid = T.oid
+		), ''),
+		COALESCE(obj_description(T.oid, 'pg_type'), '')
+	FROM
+		pg_type T
+		INNER JOIN pg_namespace N ON N.oid = T.typnamespace
+	WHERE
+		T.typtype = 'd'
+		AND N.nspname NOT IN ?
+	`
+	domainRows, err := c.db.Raw(sql, systemSchemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer domainRows.Close()
+
+	for domainRows.Next() {
+		var constraints string
+		domain := &Domain{}
+		domainRows.Scan(&domain.Schema, &domain.Name, &domain.BaseType, &domain.NotNull, &domain.Default, &constraints, &domain.Comment)
+		domain.Constraints = decodeNames(constraints)
+		types.Domains[domain.Schema+"."+domain.Name] = domain
+	}
+
+	c.types = types
+
+	return
+}
+
+func (t *UserTypes) resolve(columns Columns) {
+	for _, column := range columns {
+		if len(column.DomainName) > 0 {
+			column.Domain = t.Domains[column.DomainSchema+"."+column.DomainName]
+		}
+		column.Enum = t.Enums[column.UdtSchema+"."+column.UdtName]
+	}
+}
@@ -0,0 +1,43 @@
+package db
+
+func (c *DBConnect) dependencies(schemas []string, views tableSet) (err error) {
+	sql := `
+	SELECT DISTINCT
+		VN.nspname,
+		V.relname,
+		RN.nspname,
+		RC.relname
+	FROM
+		pg_rewrite R
+		INNER JOIN pg_class V ON V.oid = R.ev_class
+		INNER JOIN pg_namespace VN ON VN.oid = V.relnamespace
+		INNER JOIN pg_depend D
+			ON D.classid = 'pg_rewrite'::regclass
+			AND D.objid = R.oid
+			AND D.refclassid = 'pg_class'::regclass
+		INNER JOIN pg_class RC ON RC.oid = D.refobjid
+		INNER JOIN pg_namespace RN ON RN.oid = RC.relnamespace
+	WHERE
+		VN.nspname IN ?
+		AND RC.oid <> V.oid
+	ORDER BY
+		1, 2, 3, 4
+	`
+	rows, err := c.db.Raw(sql, schemas).Rows()
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, n string
+		var tn TableName
+		rows.Scan(&schema, &n, &tn.Schema, &tn.Name)
+		if info, ok := views[tableKey(schema, n)]; ok {
+			info.Dependencies = append(info.Dependencies, tn)
+		}
+	}
+
+	return
+}
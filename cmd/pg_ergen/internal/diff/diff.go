@@ -0,0 +1,397 @@
+package diff
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+const (
+	PrimaryKey = "PRIMARY KEY"
+	Unique     = "UNIQUE"
+)
+
+type Change struct {
+	Old string
+	New string
+}
+
+type Diff struct {
+	Tables []*TableDiff
+}
+
+type TableDiff struct {
+	Schema      string
+	Name        string
+	Status      string
+	Comment     *Change
+	Columns     []*ColumnDiff
+	ForeignKeys []*ForeignKeyDiff
+	Constraints []*ConstraintDiff
+	Indexes     []*IndexDiff
+
+	Old *db.TableInfo `json:"-"`
+	New *db.TableInfo `json:"-"`
+}
+
+type ColumnDiff struct {
+	Name     string
+	Status   string
+	Type     *Change
+	Nullable *Change
+	Default  *Change
+	Comment  *Change
+
+	Old *db.Column `json:"-"`
+	New *db.Column `json:"-"`
+}
+
+type ForeignKeyDiff struct {
+	Name       string
+	Status     string
+	Definition Change
+
+	Old *db.ForeignKey `json:"-"`
+	New *db.ForeignKey `json:"-"`
+}
+
+type ConstraintDiff struct {
+	Name       string
+	Type       string
+	Status     string
+	Definition Change
+
+	Old *db.Constraint `json:"-"`
+	New *db.Constraint `json:"-"`
+}
+
+type IndexDiff struct {
+	Name       string
+	Status     string
+	Definition Change
+
+	Old *db.Index `json:"-"`
+	New *db.Index `json:"-"`
+}
+
+func Compare(from *db.Model, to *db.Model) (d Diff) {
+	olds := tables(from)
+	news := tables(to)
+
+	keys := []string{}
+	for k := range olds {
+		keys = append(keys, k)
+	}
+	for k := range news {
+		if _, ok := olds[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		td := compareTable(olds[k], news[k])
+		if td != nil {
+			d.Tables = append(d.Tables, td)
+		}
+	}
+
+	return
+}
+
+func (d *Diff) Empty() bool {
+	return len(d.Tables) == 0
+}
+
+func (d *Diff) Table(schema string, n string) *TableDiff {
+	for _, td := range d.Tables {
+		if td.Schema == schema && td.Name == n {
+			return td
+		}
+	}
+	return nil
+}
+
+func (td *TableDiff) Column(n string) *ColumnDiff {
+	for _, cd := range td.Columns {
+		if cd.Name == n {
+			return cd
+		}
+	}
+	return nil
+}
+
+func (td *TableDiff) ForeignKey(n string) *ForeignKeyDiff {
+	for _, fd := range td.ForeignKeys {
+		if fd.Name == n {
+			return fd
+		}
+	}
+	return nil
+}
+
+func tables(m *db.Model) map[string]*db.TableInfo {
+	infos := map[string]*db.TableInfo{}
+	for i := range m.Tables {
+		info := &m.Tables[i]
+		infos[info.Schema+"."+info.Name] = info
+	}
+	return infos
+}
+
+func compareTable(old *db.TableInfo, new *db.TableInfo) (td *TableDiff) {
+	switch {
+	case old == nil:
+		return &TableDiff{Schema: new.Schema, Name: new.Name, Status: Added, New: new}
+	case new == nil:
+		return &TableDiff{Schema: old.Schema, Name: old.Name, Status: Removed, Old: old}
+	}
+
+	td = &TableDiff{Schema: new.Schema, Name: new.Name, Status: Changed, Old: old, New: new}
+	td.Comment = change(old.Comment, new.Comment)
+
+	for _, name := range columnNames(old, new) {
+		cd := compareColumn(name, old.Columns[name], new.Columns[name])
+		if cd != nil {
+			td.Columns = append(td.Columns, cd)
+		}
+	}
+
+	oldKeys := foreignKeys(old)
+	newKeys := foreignKeys(new)
+	for _, name := range mergedNames(oldKeys, newKeys) {
+		fd := compareForeignKey(name, oldKeys[name], newKeys[name])
+		if fd != nil {
+			td.ForeignKeys = append(td.ForeignKeys, fd)
+		}
+	}
+
+	oldConstraints := constraints(old)
+	newConstraints := constraints(new)
+	for _, name := range mergedNames(oldConstraints, newConstraints) {
+		cd := compareConstraint(name, oldConstraints[name], newConstraints[name])
+		if cd != nil {
+			td.Constraints = append(td.Constraints, cd)
+		}
+	}
+
+	oldIndexes := indexes(old)
+	newIndexes := indexes(new)
+	for _, name := range mergedNames(oldIndexes, newIndexes) {
+		ixd := compareIndex(name, oldIndexes[name], newIndexes[name])
+		if ixd != nil {
+			td.Indexes = append(td.Indexes, ixd)
+		}
+	}
+
+	if td.Comment == nil && len(td.Columns) == 0 && len(td.ForeignKeys) == 0 && len(td.Constraints) == 0 && len(td.Indexes) == 0 {
+		return nil
+	}
+	return
+}
+
+func columnNames(old *db.TableInfo, new *db.TableInfo) (names []string) {
+	order := map[string]int{}
+	for name, c := range old.Columns {
+		order[name] = c.OrdinalPosition
+		names = append(names, name)
+	}
+	for name, c := range new.Columns {
+		if _, ok := order[name]; !ok {
+			names = append(names, name)
+		}
+		order[name] = c.OrdinalPosition
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if order[names[i]] != order[names[j]] {
+			return order[names[i]] < order[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return
+}
+
+func compareColumn(name string, old *db.Column, new *db.Column) (cd *ColumnDiff) {
+	switch {
+	case old == nil:
+		return &ColumnDiff{Name: name, Status: Added, New: new}
+	case new == nil:
+		return &ColumnDiff{Name: name, Status: Removed, Old: old}
+	}
+
+	cd = &ColumnDiff{
+		Name:     name,
+		Status:   Changed,
+		Type:     change(ColumnType(old), ColumnType(new)),
+		Nullable: change(old.IsNullable, new.IsNullable),
+		Default:  change(old.ColumnDefault, new.ColumnDefault),
+		Comment:  change(old.Comment, new.Comment),
+		Old:      old,
+		New:      new,
+	}
+	if cd.Type == nil && cd.Nullable == nil && cd.Default == nil && cd.Comment == nil {
+		return nil
+	}
+	return
+}
+
+func foreignKeys(info *db.TableInfo) map[string]*db.ForeignKey {
+	keys := map[string]*db.ForeignKey{}
+	for _, fk := range info.ForeignKeys {
+		keys[fk.ConstraintName] = fk
+	}
+	return keys
+}
+
+func compareForeignKey(name string, old *db.ForeignKey, new *db.ForeignKey) *ForeignKeyDiff {
+	switch {
+	case old == nil:
+		return &ForeignKeyDiff{Name: name, Status: Added, Definition: Change{New: ForeignKeyLabel(new)}, New: new}
+	case new == nil:
+		return &ForeignKeyDiff{Name: name, Status: Removed, Definition: Change{Old: ForeignKeyLabel(old)}, Old: old}
+	}
+
+	c := change(ForeignKeyLabel(old), ForeignKeyLabel(new))
+	if c == nil {
+		return nil
+	}
+	return &ForeignKeyDiff{Name: name, Status: Changed, Definition: *c, Old: old, New: new}
+}
+
+func mergedNames[T any](olds map[string]T, news map[string]T) (names []string) {
+	for name := range olds {
+		names = append(names, name)
+	}
+	for name := range news {
+		if _, ok := olds[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func constraints(info *db.TableInfo) map[string]*db.Constraint {
+	cons := map[string]*db.Constraint{}
+	if len(info.PrimaryKey) > 0 {
+		cons[info.PrimaryKeyName] = &db.Constraint{
+			Name:       info.PrimaryKeyName,
+			Type:       PrimaryKey,
+			Columns:    info.PrimaryKey,
+			Definition: "PRIMARY KEY (" + strings.Join(info.PrimaryKey, ", ") + ")",
+		}
+	}
+	for _, key := range info.UniqueKeys {
+		if key.IsIndex {
+			continue
+		}
+		cons[key.Name] = &db.Constraint{
+			Name:       key.Name,
+			Type:       Unique,
+			Columns:    key.Columns,
+			Definition: "UNIQUE (" + strings.Join(key.Columns, ", ") + ")",
+		}
+	}
+	for _, con := range info.Constraints {
+		cons[con.Name] = con
+	}
+	return cons
+}
+
+func compareConstraint(name string, old *db.Constraint, new *db.Constraint) *ConstraintDiff {
+	switch {
+	case old == nil:
+		return &ConstraintDiff{Name: name, Type: new.Type, Status: Added, Definition: Change{New: new.Definition}, New: new}
+	case new == nil:
+		return &ConstraintDiff{Name: name, Type: old.Type, Status: Removed, Definition: Change{Old: old.Definition}, Old: old}
+	}
+
+	c := change(old.Definition, new.Definition)
+	if c == nil {
+		return nil
+	}
+	return &ConstraintDiff{Name: name, Type: new.Type, Status: Changed, Definition: *c, Old: old, New: new}
+}
+
+func indexes(info *db.TableInfo) map[string]*db.Index {
+	ixs := map[string]*db.Index{}
+	for _, ix := range info.Indexes {
+		if ix.IsPrimary || slices.ContainsFunc(info.UniqueKeys, func(key *db.UniqueKey) bool { return key.Name == ix.Name && !key.IsIndex }) {
+			continue
+		}
+		ixs[ix.Name] = ix
+	}
+	return ixs
+}
+
+func IndexLabel(ix *db.Index) string {
+	label := ""
+	if ix.IsUnique {
+		label = "UNIQUE "
+	}
+	label += "USING " + ix.Method + " (" + strings.Join(ix.Columns, ", ") + ")"
+	if len(ix.Predicate) > 0 {
+		label += " WHERE " + ix.Predicate
+	}
+	return label
+}
+
+func compareIndex(name string, old *db.Index, new *db.Index) *IndexDiff {
+	switch {
+	case old == nil:
+		return &IndexDiff{Name: name, Status: Added, Definition: Change{New: IndexLabel(new)}, New: new}
+	case new == nil:
+		return &IndexDiff{Name: name, Status: Removed, Definition: Change{Old: IndexLabel(old)}, Old: old}
+	}
+
+	c := change(IndexLabel(old), IndexLabel(new))
+	if c == nil {
+		return nil
+	}
+	return &IndexDiff{Name: name, Status: Changed, Definition: *c, Old: old, New: new}
+}
+
+func change(old string, new string) *Change {
+	if old == new {
+		return nil
+	}
+	return &Change{old, new}
+}
+
+func ColumnType(c *db.Column) string {
+	if c.Domain != nil {
+		return c.Domain.Schema + "." + c.Domain.Name
+	}
+	if len(c.DomainName) > 0 {
+		return c.DomainSchema + "." + c.DomainName
+	}
+	return c.FormatType(nil)
+}
+
+func ForeignKeyLabel(fk *db.ForeignKey) string {
+	label := "(" + strings.Join(fk.Columns, ", ") + ") REFERENCES " + fk.TableSchema + "." + fk.TableName + "(" + strings.Join(fk.ReferencedColumns, ", ") + ")"
+	if len(fk.MatchOption) > 0 && fk.MatchOption != "NONE" {
+		label += " MATCH " + fk.MatchOption
+	}
+	if len(fk.UpdateRule) > 0 && fk.UpdateRule != "NO ACTION" {
+		label += " ON UPDATE " + fk.UpdateRule
+	}
+	if len(fk.DeleteRule) > 0 && fk.DeleteRule != "NO ACTION" {
+		label += " ON DELETE " + fk.DeleteRule
+	}
+	if fk.IsDeferrable {
+		label += " DEFERRABLE"
+		if fk.InitiallyDeferred {
+			label += " INITIALLY DEFERRED"
+		}
+	}
+	return label
+}
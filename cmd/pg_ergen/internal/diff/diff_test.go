@@ -0,0 +1,217 @@
+package diff
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+const baseDDL = `
+CREATE TABLE public.users (
+	id integer PRIMARY KEY,
+	name varchar(80) NOT NULL,
+	email text DEFAULT ''
+);
+CREATE TABLE public.orders (
+	id integer PRIMARY KEY,
+	user_id integer CONSTRAINT orders_user_id_fkey REFERENCES public.users (id)
+);
+COMMENT ON TABLE public.users IS 'people';
+COMMENT ON COLUMN public.users.name IS 'display name';
+`
+
+func snapshotFile(t *testing.T, path string) (model db.Model) {
+	t.Helper()
+	model, err := (&db.DDLSource{Path: path}).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func snapshotDDL(t *testing.T, ddl string) db.Model {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(ddl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return snapshotFile(t, path)
+}
+
+func summarize(d *Diff) (lines []string) {
+	changes := func(prefix string, fields map[string]*Change) {
+		for _, name := range []string{"type", "nullable", "default", "comment"} {
+			if c := fields[name]; c != nil {
+				lines = append(lines, prefix+" "+name+" "+c.Old+" -> "+c.New)
+			}
+		}
+	}
+	for _, td := range d.Tables {
+		lines = append(lines, td.Status+" table "+td.Schema+"."+td.Name)
+		changes("  table", map[string]*Change{"comment": td.Comment})
+		for _, cd := range td.Columns {
+			lines = append(lines, "  "+cd.Status+" column "+cd.Name)
+			changes("    column "+cd.Name, map[string]*Change{"type": cd.Type, "nullable": cd.Nullable, "default": cd.Default, "comment": cd.Comment})
+		}
+		for _, fd := range td.ForeignKeys {
+			lines = append(lines, "  "+fd.Status+" foreign key "+fd.Name+" "+fd.Definition.Old+" -> "+fd.Definition.New)
+		}
+		for _, cd := range td.Constraints {
+			lines = append(lines, "  "+cd.Status+" "+cd.Type+" "+cd.Name+" "+cd.Definition.Old+" -> "+cd.Definition.New)
+		}
+		for _, ixd := range td.Indexes {
+			lines = append(lines, "  "+ixd.Status+" index "+ixd.Name+" "+ixd.Definition.Old+" -> "+ixd.Definition.New)
+		}
+	}
+	return
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		want []string
+	}{
+		{"unchanged", baseDDL, nil},
+		{"added table", baseDDL + `CREATE TABLE billing.users (id integer);`, []string{
+			"added table billing.users",
+		}},
+		{"removed table", `
+CREATE TABLE public.users (id integer PRIMARY KEY, name varchar(80) NOT NULL, email text DEFAULT '');
+COMMENT ON TABLE public.users IS 'people';
+COMMENT ON COLUMN public.users.name IS 'display name';
+`, []string{
+			"removed table public.orders",
+		}},
+		{"columns", baseDDL + `
+ALTER TABLE public.users ADD COLUMN age smallint;
+ALTER TABLE public.users DROP COLUMN email;
+`, []string{
+			"changed table public.users",
+			"  removed column email",
+			"  added column age",
+		}},
+		{"type", baseDDL + `ALTER TABLE public.users ALTER COLUMN name TYPE text;`, []string{
+			"changed table public.users",
+			"  changed column name",
+			"    column name type varchar(80) -> text",
+		}},
+		{"nullability and default", baseDDL + `
+ALTER TABLE public.users ALTER COLUMN name DROP NOT NULL;
+ALTER TABLE public.users ALTER COLUMN email SET DEFAULT 'none';
+`, []string{
+			"changed table public.users",
+			"  changed column name",
+			"    column name nullable NO -> YES",
+			"  changed column email",
+			"    column email default '' -> 'none'",
+		}},
+		{"comments", baseDDL + `
+COMMENT ON TABLE public.users IS 'customers';
+COMMENT ON COLUMN public.users.name IS NULL;
+`, []string{
+			"changed table public.users",
+			"  table comment people -> customers",
+			"  changed column name",
+			"    column name comment display name -> ",
+		}},
+		{"changed foreign key", baseDDL + `
+ALTER TABLE public.orders DROP CONSTRAINT orders_user_id_fkey;
+ALTER TABLE public.orders ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users (id) ON DELETE CASCADE;
+`, []string{
+			"changed table public.orders",
+			"  changed foreign key orders_user_id_fkey (user_id) REFERENCES public.users(id) -> (user_id) REFERENCES public.users(id) ON DELETE CASCADE",
+		}},
+		{"replaced foreign key", baseDDL + `
+ALTER TABLE public.orders DROP CONSTRAINT orders_user_id_fkey;
+ALTER TABLE public.orders ADD CONSTRAINT orders_owner_fkey FOREIGN KEY (user_id) REFERENCES public.users (id);
+`, []string{
+			"changed table public.orders",
+			"  added foreign key orders_owner_fkey  -> (user_id) REFERENCES public.users(id)",
+			"  removed foreign key orders_user_id_fkey (user_id) REFERENCES public.users(id) -> ",
+		}},
+		{"constraints and indexes", baseDDL + `
+ALTER TABLE public.users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE public.users ADD CONSTRAINT users_name_check CHECK (name <> '');
+CREATE INDEX orders_user_id_idx ON public.orders (user_id);
+`, []string{
+			"changed table public.orders",
+			"  added index orders_user_id_idx  -> USING btree (user_id)",
+			"changed table public.users",
+			"  added UNIQUE users_email_key  -> UNIQUE (email)",
+			"  added CHECK users_name_check  -> CHECK (name <> '')",
+		}},
+	}
+
+	from := snapshotDDL(t, baseDDL)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := snapshotDDL(t, tt.to)
+			d := Compare(&from, &to)
+			if got := summarize(&d); !slices.Equal(got, tt.want) {
+				t.Errorf("Compare:\n got %q\nwant %q", got, tt.want)
+			}
+			if d.Empty() != (len(tt.want) == 0) {
+				t.Errorf("Empty() = %v", d.Empty())
+			}
+		})
+	}
+}
+
+func TestCompareLookup(t *testing.T) {
+	from := snapshotDDL(t, baseDDL)
+	to := snapshotDDL(t, baseDDL+`ALTER TABLE public.users ALTER COLUMN name TYPE text;`)
+	d := Compare(&from, &to)
+	td := d.Table("public", "users")
+	if td == nil || td.Old == nil || td.New == nil {
+		t.Fatalf("Table(public, users) = %+v", td)
+	}
+	if cd := td.Column("name"); cd == nil || cd.Old == nil || cd.New == nil {
+		t.Errorf("Column(name) = %+v", cd)
+	}
+	if td.Column("email") != nil || d.Table("public", "orders") != nil || td.ForeignKey("orders_user_id_fkey") != nil {
+		t.Error("unchanged objects reported")
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	from := snapshotFile(t, filepath.Join("..", "sqlgen", "testdata", "migrate_from.sql"))
+	to := snapshotFile(t, filepath.Join("..", "sqlgen", "testdata", "migrate_to.sql"))
+	d := Compare(&from, &to)
+
+	tests := []struct {
+		golden string
+		write  func(*bytes.Buffer) error
+	}{
+		{"diff.txt.golden", func(b *bytes.Buffer) error { d.WriteText(b); return nil }},
+		{"diff.json.golden", func(b *bytes.Buffer) error { return d.WriteJSON(b) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", tt.golden, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var statusMarks = map[string]string{
+	Added:   "+",
+	Removed: "-",
+	Changed: "~",
+}
+
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(d)
+}
+
+func (d *Diff) WriteText(w io.Writer) {
+	for _, td := range d.Tables {
+		fmt.Fprintf(w, "%s table %s.%s\n", statusMarks[td.Status], td.Schema, td.Name)
+		if td.Comment != nil {
+			fmt.Fprintf(w, "    ~ comment: %q → %q\n", td.Comment.Old, td.Comment.New)
+		}
+		for _, cd := range td.Columns {
+			switch cd.Status {
+			case Added:
+				fmt.Fprintf(w, "    + column %s %s%s\n", cd.Name, ColumnType(cd.New), nullLabel(cd.New.IsNullable))
+			case Removed:
+				fmt.Fprintf(w, "    - column %s %s%s\n", cd.Name, ColumnType(cd.Old), nullLabel(cd.Old.IsNullable))
+			case Changed:
+				if cd.Type != nil {
+					fmt.Fprintf(w, "    ~ column %s: type %s → %s\n", cd.Name, cd.Type.Old, cd.Type.New)
+				}
+				if cd.Nullable != nil {
+					fmt.Fprintf(w, "    ~ column %s:%s →%s\n", cd.Name, nullLabel(cd.Nullable.Old), nullLabel(cd.Nullable.New))
+				}
+				if cd.Default != nil {
+					fmt.Fprintf(w, "    ~ column %s: default %s → %s\n", cd.Name, defaultLabel(cd.Default.Old), defaultLabel(cd.Default.New))
+				}
+				if cd.Comment != nil {
+					fmt.Fprintf(w, "    ~ column %s: comment %q → %q\n", cd.Name, cd.Comment.Old, cd.Comment.New)
+				}
+			}
+		}
+		for _, fd := range td.ForeignKeys {
+			switch fd.Status {
+			case Added:
+				fmt.Fprintf(w, "    + foreign key %s %s\n", fd.Name, fd.Definition.New)
+			case Removed:
+				fmt.Fprintf(w, "    - foreign key %s %s\n", fd.Name, fd.Definition.Old)
+			case Changed:
+				fmt.Fprintf(w, "    ~ foreign key %s: %s → %s\n", fd.Name, fd.Definition.Old, fd.Definition.New)
+			}
+		}
+		for _, cd := range td.Constraints {
+			switch cd.Status {
+			case Added:
+				fmt.Fprintf(w, "    + constraint %s %s\n", cd.Name, cd.Definition.New)
+			case Removed:
+				fmt.Fprintf(w, "    - constraint %s %s\n", cd.Name, cd.Definition.Old)
+			case Changed:
+				fmt.Fprintf(w, "    ~ constraint %s: %s → %s\n", cd.Name, cd.Definition.Old, cd.Definition.New)
+			}
+		}
+		for _, ixd := range td.Indexes {
+			switch ixd.Status {
+			case Added:
+				fmt.Fprintf(w, "    + index %s %s\n", ixd.Name, ixd.Definition.New)
+			case Removed:
+				fmt.Fprintf(w, "    - index %s %s\n", ixd.Name, ixd.Definition.Old)
+			case Changed:
+				fmt.Fprintf(w, "    ~ index %s: %s → %s\n", ixd.Name, ixd.Definition.Old, ixd.Definition.New)
+			}
+		}
+	}
+}
+
+func defaultLabel(value string) string {
+	if len(value) == 0 {
+		return "(none)"
+	}
+	return value
+}
+
+func nullLabel(isNullable string) string {
+	if isNullable == "NO" {
+		return " NOT NULL"
+	}
+	return " NULL"
+}
@@ -0,0 +1,382 @@
+package gormgen
+
+import (
+	"fmt"
+	"go/format"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/jinzhu/inflection"
+	"gorm.io/gorm/schema"
+)
+
+var goTypes = map[string]string{
+	"smallint":                    "int16",
+	"integer":                     "int32",
+	"bigint":                      "int64",
+	"boolean":                     "bool",
+	"text":                        "string",
+	"character varying":           "string",
+	"numeric":                     "float64",
+	"bytea":                       "[]byte",
+	"timestamp with time zone":    "time.Time",
+	"timestamp without time zone": "time.Time",
+	"date":                        "time.Time",
+	"real":                        "float32",
+	"double precision":            "float64",
+}
+
+var quotedDefault = regexp.MustCompile(`^'((?:[^']|'')*)'(::[\w ."]+)?$`)
+
+var tagEscaper = strings.NewReplacer(";", `\;`)
+
+type structInfo struct {
+	name    string
+	table   string
+	fields  map[string]string
+	primary []string
+}
+
+type generator struct {
+	naming  schema.NamingStrategy
+	structs map[string]*structInfo
+}
+
+func Generate(pkg string, tables []db.TableInfo) (files map[string][]byte, err error) {
+	g := &generator{structs: map[string]*structInfo{}}
+	taken := map[string]bool{}
+	for i := range tables {
+		info := &tables[i]
+		s := &structInfo{name: goName(inflection.Singular(info.Name)), table: info.Name}
+		if taken[s.name] {
+			s.name = goName(info.Schema) + s.name
+		}
+		taken[s.name] = true
+		if info.Schema != "public" {
+			s.table = info.Schema + "." + info.Name
+		}
+		s.fields = fieldNames(info)
+		for _, column := range info.PrimaryKey {
+			s.primary = append(s.primary, s.fields[column])
+		}
+		g.structs[tableKey(info.Schema, info.Name)] = s
+	}
+
+	files = map[string][]byte{}
+	for i := range tables {
+		info := &tables[i]
+		var src []byte
+		src, err = format.Source(g.file(pkg, info))
+		if err != nil {
+			err = fmt.Errorf("%s.%s: %w", info.Schema, info.Name, err)
+			return
+		}
+		name := info.Name
+		if info.Schema != "public" {
+			name = info.Schema + "_" + name
+		}
+		files[fileName(name)] = src
+	}
+
+	return
+}
+
+func tableKey(schema string, n string) string {
+	return schema + "." + n
+}
+
+func sortedColumns(info *db.TableInfo) (columns []*db.Column) {
+	for _, column := range info.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].OrdinalPosition < columns[j].OrdinalPosition
+	})
+	return
+}
+
+func fieldNames(info *db.TableInfo) (fields map[string]string) {
+	fields = map[string]string{}
+	taken := map[string]bool{"TableName": true}
+	for _, column := range sortedColumns(info) {
+		name := goName(column.ColumnName)
+		for i := 2; taken[name]; i++ {
+			name = goName(column.ColumnName) + strconv.Itoa(i)
+		}
+		taken[name] = true
+		fields[column.ColumnName] = name
+	}
+	return
+}
+
+func (g *generator) file(pkg string, info *db.TableInfo) []byte {
+	s := g.structs[tableKey(info.Schema, info.Name)]
+	tags := g.columnTags(info, s)
+
+	var body strings.Builder
+	if len(info.Comment) > 0 {
+		for _, line := range strings.Split(info.Comment, "\n") {
+			fmt.Fprintf(&body, "// %s\n", line)
+		}
+	}
+	fmt.Fprintf(&body, "type %s struct {\n", s.name)
+	usesTime := false
+	for _, column := range sortedColumns(info) {
+		typ, _ := goType(column)
+		usesTime = usesTime || typ == "time.Time"
+		if column.IsNullable == "YES" && !column.IsPrimaryKey && typ != "[]byte" {
+			typ = "*" + typ
+		}
+		fmt.Fprintf(&body, "\t%s %s %s\n", s.fields[column.ColumnName], typ, tagLiteral(tags[column.ColumnName]))
+	}
+	if associations := g.associations(info, s); len(associations) > 0 {
+		body.WriteString("\n")
+		body.WriteString(associations)
+	}
+	body.WriteString("}\n")
+
+	if s.table != g.naming.TableName(s.name) {
+		fmt.Fprintf(&body, "\nfunc (%s) TableName() string {\n\treturn %q\n}\n", s.name, s.table)
+	}
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	if usesTime {
+		src.WriteString("import \"time\"\n\n")
+	}
+	src.WriteString(body.String())
+	return []byte(src.String())
+}
+
+func goType(column *db.Column) (typ string, tags []string) {
+	typ, ok := goTypes[column.DataType]
+	if !ok {
+		typ = "string"
+	}
+	if len(column.DomainName) > 0 {
+		name := column.DomainName
+		if len(column.DomainSchema) > 0 && column.DomainSchema != "public" {
+			name = column.DomainSchema + "." + name
+		}
+		return typ, []string{"type:" + name}
+	}
+
+	switch column.DataType {
+	case "smallint", "integer", "bigint", "boolean", "text", "bytea":
+	case "character varying":
+		if len(column.CharacterMaximumLength) > 0 {
+			tags = append(tags, "size:"+column.CharacterMaximumLength)
+		} else {
+			tags = append(tags, "type:varchar")
+		}
+	case "numeric":
+		if len(column.NumericPrecision) > 0 {
+			tags = append(tags, "precision:"+column.NumericPrecision)
+			if len(column.NumericScale) > 0 && column.NumericScale != "0" {
+				tags = append(tags, "scale:"+column.NumericScale)
+			}
+		}
+	case "timestamp with time zone":
+		if len(column.DatetimePrecision) > 0 && column.DatetimePrecision != "6" {
+			tags = append(tags, "precision:"+column.DatetimePrecision)
+		}
+	default:
+		tags = append(tags, "type:"+column.FormatType(nil))
+	}
+	return
+}
+
+func defaultValue(column *db.Column, typ string) (value string, ok bool) {
+	value = column.ColumnDefault
+	if len(value) == 0 || strings.HasPrefix(value, "nextval(") || column.IsIdentity == "YES" || column.IsGenerated == "ALWAYS" {
+		return "", false
+	}
+	if typ == "string" {
+		if m := quotedDefault.FindStringSubmatch(value); m != nil {
+			value = strings.ReplaceAll(m[1], "''", "'")
+			if len(value) == 0 {
+				value = "''"
+			}
+		}
+	}
+	return value, true
+}
+
+func (g *generator) columnTags(info *db.TableInfo, s *structInfo) (tags map[string][]string) {
+	tags = map[string][]string{}
+	add := func(column string, tag string) {
+		tags[column] = append(tags[column], tag)
+	}
+
+	for _, column := range sortedColumns(info) {
+		name := column.ColumnName
+		if g.naming.ColumnName("", s.fields[name]) != name {
+			add(name, "column:"+name)
+		}
+		typ, typeTags := goType(column)
+		for _, tag := range typeTags {
+			add(name, tag)
+		}
+		if column.IsPrimaryKey {
+			add(name, "primaryKey")
+			integer := typ == "int16" || typ == "int32" || typ == "int64"
+			if integer && len(info.PrimaryKey) == 1 && !strings.HasPrefix(column.ColumnDefault, "nextval(") && column.IsIdentity != "YES" {
+				add(name, "autoIncrement:false")
+			}
+		} else if column.IsNullable == "NO" {
+			add(name, "not null")
+		}
+		if value, ok := defaultValue(column, typ); ok {
+			add(name, "default:"+tagEscaper.Replace(value))
+		}
+	}
+
+	constraintIndexes := map[string]bool{}
+	for _, key := range info.UniqueKeys {
+		if key.IsIndex {
+			continue
+		}
+		constraintIndexes[key.Name] = true
+		if len(key.Columns) == 1 && key.Name == info.Name+"_"+key.Columns[0]+"_key" {
+			add(key.Columns[0], "unique")
+			continue
+		}
+		for i, column := range key.Columns {
+			add(column, fmt.Sprintf("uniqueIndex:%s,priority:%d", key.Name, i+1))
+		}
+	}
+
+	for _, ix := range info.Indexes {
+		if ix.IsPrimary || constraintIndexes[ix.Name] || slices.ContainsFunc(ix.Columns, func(column string) bool {
+			_, ok := info.Columns[column]
+			return !ok
+		}) {
+			continue
+		}
+		kind := "index"
+		if ix.IsUnique {
+			kind = "uniqueIndex"
+		}
+		var options []string
+		if len(ix.Method) > 0 && ix.Method != "btree" {
+			options = append(options, "type:"+ix.Method)
+		}
+		if len(ix.Predicate) > 0 && !strings.Contains(ix.Predicate, ";") {
+			options = append(options, "where:"+strings.ReplaceAll(ix.Predicate, ",", `\,`))
+		}
+		if len(ix.Comment) > 0 && !strings.Contains(ix.Comment, ";") {
+			options = append(options, "comment:"+strings.ReplaceAll(ix.Comment, ",", `\,`))
+		}
+		for i, column := range ix.Columns {
+			name := ix.Name
+			if len(ix.Columns) == 1 && name == g.naming.IndexName(s.table, s.fields[column]) {
+				name = ""
+			}
+			opts := options
+			if len(ix.Columns) > 1 {
+				opts = append([]string{fmt.Sprintf("priority:%d", i+1)}, options...)
+			}
+			tag := kind
+			if len(name) > 0 || len(opts) > 0 {
+				tag += ":" + strings.Join(append([]string{name}, opts...), ",")
+			}
+			add(column, tag)
+		}
+	}
+
+	for _, con := range info.Constraints {
+		expr, ok := strings.CutPrefix(con.Definition, "CHECK ")
+		if !ok || len(con.Columns) == 0 {
+			continue
+		}
+		expr = strings.TrimSuffix(strings.TrimPrefix(expr, "("), ")")
+		add(con.Columns[0], "check:"+con.Name+","+tagEscaper.Replace(expr))
+	}
+
+	for _, column := range sortedColumns(info) {
+		if len(column.Comment) > 0 {
+			add(column.ColumnName, "comment:"+tagEscaper.Replace(column.Comment))
+		}
+	}
+
+	return
+}
+
+func (g *generator) associations(info *db.TableInfo, s *structInfo) string {
+	var b strings.Builder
+	taken := map[string]bool{"TableName": true}
+	for _, name := range s.fields {
+		taken[name] = true
+	}
+
+	for _, fk := range info.ForeignKeys {
+		target, ok := g.structs[tableKey(fk.TableSchema, fk.TableName)]
+		if !ok {
+			continue
+		}
+		name := goName(inflection.Singular(fk.TableName))
+		if len(fk.Columns) == 1 && strings.HasSuffix(fk.Columns[0], "_id") {
+			name = goName(strings.TrimSuffix(fk.Columns[0], "_id"))
+		}
+		base := name
+		if taken[name] {
+			name = base + "Ref"
+		}
+		for i := 2; taken[name]; i++ {
+			name = base + "Ref" + strconv.Itoa(i)
+		}
+		taken[name] = true
+
+		var foreignKeys, references, guessed []string
+		for i, column := range fk.Columns {
+			foreignKeys = append(foreignKeys, s.fields[column])
+			if i < len(fk.ReferencedColumns) {
+				references = append(references, target.fields[fk.ReferencedColumns[i]])
+				guessed = append(guessed, name+target.fields[fk.ReferencedColumns[i]])
+			}
+		}
+
+		var settings []string
+		if !slices.Equal(foreignKeys, guessed) {
+			settings = append(settings, "foreignKey:"+strings.Join(foreignKeys, ","))
+		}
+		if !slices.Equal(references, target.primary) {
+			settings = append(settings, "references:"+strings.Join(references, ","))
+		}
+		var constraint []string
+		if fk.ConstraintName != g.naming.RelationshipFKName(schema.Relationship{Name: name, Schema: &schema.Schema{Table: s.table}}) {
+			constraint = append(constraint, fk.ConstraintName)
+		}
+		if len(fk.UpdateRule) > 0 && fk.UpdateRule != "NO ACTION" {
+			constraint = append(constraint, "OnUpdate:"+fk.UpdateRule)
+		}
+		if len(fk.DeleteRule) > 0 && fk.DeleteRule != "NO ACTION" {
+			constraint = append(constraint, "OnDelete:"+fk.DeleteRule)
+		}
+		if len(constraint) == 1 && constraint[0] == fk.ConstraintName {
+			constraint = append(constraint, "")
+		}
+		if len(constraint) > 0 {
+			settings = append(settings, "constraint:"+strings.Join(constraint, ","))
+		}
+
+		fmt.Fprintf(&b, "\t%s *%s %s\n", name, target.name, tagLiteral(settings))
+	}
+
+	return b.String()
+}
+
+func tagLiteral(settings []string) string {
+	if len(settings) == 0 {
+		return ""
+	}
+	tag := "gorm:" + strconv.Quote(strings.Join(settings, ";"))
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
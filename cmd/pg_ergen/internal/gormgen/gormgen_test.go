@@ -0,0 +1,176 @@
+package gormgen
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func generateFile(t *testing.T, path string) (files map[string][]byte) {
+	t.Helper()
+	model, err := (&db.DDLSource{Path: path}).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = Generate("models", model.Tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestGenerateGolden(t *testing.T) {
+	files := generateFile(t, filepath.Join("testdata", "models.sql"))
+	names := slices.Sorted(maps.Keys(files))
+	want := []string{"billing_users.go", "order_items.go", "orders.go", "users.go"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(path, files[name], 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(files[name]); got != string(golden) {
+				t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", name, got, golden)
+			}
+		})
+	}
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	files := generateFile(t, filepath.Join("testdata", "models.sql"))
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		f, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("models", fset, parsed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"User", "PublicUser", "Order", "OrderItem"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("type %s not declared", name)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"user_id", "UserID"},
+		{"user-id", "UserID"},
+		{"api_url", "APIURL"},
+		{"Weird Col", "WeirdCol"},
+		{"2fa_secret", "X2faSecret"},
+		{"_", "X"},
+		{"émile", "Émile"},
+	}
+	for _, tt := range tests {
+		if got := goName(tt.in); got != tt.want {
+			t.Errorf("goName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"users", "users.go"},
+		{"billing_Users", "billing_users.go"},
+		{"order items", "order_items.go"},
+		{"unit_test", "unit_test_table.go"},
+		{"events_linux", "events_linux_table.go"},
+		{"logs_amd64", "logs_amd64_table.go"},
+		{"latest", "latest.go"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.in); got != tt.want {
+			t.Errorf("fileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"models", "models"},
+		{"My-Models", "mymodels"},
+		{"2024", "models"},
+		{"", "models"},
+	}
+	for _, tt := range tests {
+		if got := PackageName(tt.in); got != tt.want {
+			t.Errorf("PackageName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	tables := []db.TableInfo{
+		{Schema: "public", Name: "users", Columns: map[string]*db.Column{
+			"user_id":   {ColumnName: "user_id", OrdinalPosition: 1, DataType: "integer", IsNullable: "NO"},
+			"user-id":   {ColumnName: "user-id", OrdinalPosition: 2, DataType: "integer", IsNullable: "NO"},
+			"user id":   {ColumnName: "user id", OrdinalPosition: 3, DataType: "integer", IsNullable: "NO"},
+			"TableName": {ColumnName: "TableName", OrdinalPosition: 4, DataType: "text", IsNullable: "NO"},
+		}},
+		{Schema: "billing", Name: "users"},
+		{Schema: "audit", Name: "user"},
+	}
+	fields := fieldNames(&tables[0])
+	want := map[string]string{"user_id": "UserID", "user-id": "UserID2", "user id": "UserID3", "TableName": "TableName2"}
+	if !maps.Equal(fields, want) {
+		t.Errorf("fieldNames = %v, want %v", fields, want)
+	}
+
+	files, err := Generate("models", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := slices.Sorted(maps.Keys(files))
+	if want := []string{"audit_user.go", "billing_users.go", "users.go"}; !slices.Equal(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	for name, decl := range map[string]string{
+		"users.go":         "type User struct",
+		"billing_users.go": "type BillingUser struct",
+		"audit_user.go":    "type AuditUser struct",
+	} {
+		if !strings.Contains(string(files[name]), decl) {
+			t.Errorf("%s does not declare %q:\n%s", name, decl, files[name])
+		}
+	}
+}
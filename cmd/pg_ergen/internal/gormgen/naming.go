@@ -0,0 +1,72 @@
+package gormgen
+
+import (
+	"strings"
+	"unicode"
+)
+
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "QPS": true,
+	"RAM": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TLS": true,
+	"TTL": true, "UID": true, "UI": true, "UUID": true, "URI": true, "URL": true, "UTF8": true,
+	"VM": true, "XML": true,
+}
+
+var buildSuffixes = []string{
+	"test", "aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js", "linux",
+	"netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows",
+	"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le", "mipsle",
+	"ppc64", "ppc64le", "riscv64", "s390x", "wasm",
+}
+
+func goName(s string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+
+	name := b.String()
+	if len(name) == 0 || !unicode.IsUpper([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+func fileName(s string) string {
+	name := strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s))
+	for _, suffix := range buildSuffixes {
+		if strings.HasSuffix(name, "_"+suffix) {
+			name += "_table"
+			break
+		}
+	}
+	return name + ".go"
+}
+
+func PackageName(s string) string {
+	name := strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s))
+	if len(name) == 0 || !unicode.IsLetter([]rune(name)[0]) {
+		name = "models"
+	}
+	return name
+}
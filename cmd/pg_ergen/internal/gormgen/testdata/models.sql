@@ -0,0 +1,38 @@
+CREATE SCHEMA billing;
+
+CREATE TABLE public.users (
+	id bigserial PRIMARY KEY,
+	email varchar(320) NOT NULL UNIQUE,
+	name varchar(80) DEFAULT 'anon'::character varying,
+	nickname text,
+	avatar bytea,
+	score numeric(10,2) DEFAULT 0 NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	"user-id" integer,
+	user_id integer,
+	"TableName" text
+);
+COMMENT ON TABLE public.users IS 'Registered users';
+COMMENT ON COLUMN public.users.name IS 'Display; name';
+
+CREATE TABLE public.orders (
+	id integer NOT NULL,
+	placed_at timestamptz NOT NULL,
+	user_id bigint NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	note varchar,
+	PRIMARY KEY (id, placed_at)
+);
+
+CREATE TABLE public.order_items (
+	id integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	order_id integer NOT NULL,
+	placed_at timestamptz NOT NULL,
+	"order" integer,
+	quantity smallint DEFAULT 1 NOT NULL,
+	CONSTRAINT order_items_order_fkey FOREIGN KEY (order_id, placed_at) REFERENCES public.orders (id, placed_at)
+);
+
+CREATE TABLE billing.users (
+	code text PRIMARY KEY,
+	owner_id bigint REFERENCES public.users (id)
+);
@@ -0,0 +1,313 @@
+package sqlgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+)
+
+type migration struct {
+	destructive bool
+	enums       []string
+	phases      [][]string
+}
+
+func (m *migration) phase() {
+	m.phases = append(m.phases, nil)
+}
+
+func (m *migration) add(format string, args ...any) {
+	last := len(m.phases) - 1
+	m.phases[last] = append(m.phases[last], fmt.Sprintf(format, args...))
+}
+
+func (m *migration) destroy(format string, args ...any) {
+	if !m.destructive {
+		format = "-- " + format
+	}
+	m.add(format, args...)
+}
+
+func WriteMigration(w io.Writer, from *db.Model, to *db.Model, d *diff.Diff, destructive bool) (err error) {
+	m := &migration{destructive: destructive}
+
+	var added, removed []*db.TableInfo
+	var changed []*diff.TableDiff
+	for _, td := range d.Tables {
+		switch td.Status {
+		case diff.Added:
+			added = append(added, td.New)
+		case diff.Removed:
+			removed = append(removed, td.Old)
+		case diff.Changed:
+			changed = append(changed, td)
+		}
+	}
+
+	m.phase()
+	for _, td := range changed {
+		table := Qualified(td.Schema, td.Name)
+		for _, fd := range td.ForeignKeys {
+			if fd.Status != diff.Added {
+				m.add("ALTER TABLE %s DROP CONSTRAINT %s;", table, Ident(fd.Name))
+			}
+		}
+		for _, cd := range td.Constraints {
+			if cd.Status != diff.Added {
+				m.add("ALTER TABLE %s DROP CONSTRAINT %s;", table, Ident(cd.Name))
+			}
+		}
+		for _, ixd := range td.Indexes {
+			if ixd.Status != diff.Added {
+				m.add("DROP INDEX %s;", Qualified(td.Schema, ixd.Name))
+			}
+		}
+	}
+
+	m.phase()
+	m.schemas(from, added)
+	m.userTypes(from, to)
+
+	tables := map[string]bool{}
+	for _, info := range to.Tables {
+		tables[tableKey(info.Schema, info.Name)] = true
+	}
+	var buf bytes.Buffer
+	sw := &schemaWriter{w: &buf, tables: tables, created: map[string]bool{}}
+	sw.sequences(added)
+	for _, info := range DependencyOrder(added) {
+		sw.table(info)
+	}
+	if sw.err != nil {
+		return sw.err
+	}
+	m.phase()
+	if buf.Len() > 0 {
+		m.add("%s", strings.TrimSuffix(buf.String(), "\n\n"))
+	}
+
+	m.phase()
+	for _, td := range changed {
+		m.columns(td)
+	}
+
+	m.phase()
+	for _, td := range changed {
+		info := td.New
+		table := Qualified(td.Schema, td.Name)
+		for _, cd := range td.Constraints {
+			if cd.Status == diff.Removed {
+				continue
+			}
+			switch cd.New.Type {
+			case diff.PrimaryKey:
+				m.add("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, Ident(cd.Name), PrimaryKeyDefinition(info))
+			case diff.Unique:
+				m.add("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, Ident(cd.Name), UniqueDefinition(&db.UniqueKey{Columns: cd.New.Columns}))
+			default:
+				m.add("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, Ident(cd.Name), ConstraintDefinition(cd.New))
+			}
+		}
+		for _, ixd := range td.Indexes {
+			if ixd.Status != diff.Removed {
+				m.add("%s", CreateIndex(info, ixd.New))
+			}
+		}
+	}
+
+	m.phase()
+	for _, td := range changed {
+		for _, fd := range td.ForeignKeys {
+			if fd.Status != diff.Removed {
+				m.add("ALTER TABLE %s ADD CONSTRAINT %s %s;", Qualified(td.Schema, td.Name), Ident(fd.Name), ForeignKeyDefinition(fd.New))
+			}
+		}
+	}
+	for _, stmt := range sw.pending {
+		m.add("%s", stmt)
+	}
+
+	m.phase()
+	for _, td := range changed {
+		m.comments(td)
+	}
+
+	m.phase()
+	for _, td := range changed {
+		if _, ok := kindLabels[td.New.Kind]; ok {
+			continue
+		}
+		for _, cd := range td.Columns {
+			if cd.Status == diff.Removed {
+				m.destroy("ALTER TABLE %s DROP COLUMN %s;", Qualified(td.Schema, td.Name), Ident(cd.Name))
+			}
+		}
+	}
+	ordered := DependencyOrder(removed)
+	slices.Reverse(ordered)
+	for _, info := range ordered {
+		m.destroy("DROP %s %s;", objectKind(info), Qualified(info.Schema, info.Name))
+	}
+
+	return m.write(w)
+}
+
+func (m *migration) write(w io.Writer) (err error) {
+	var phases [][]string
+	for _, stmts := range m.phases {
+		if len(stmts) > 0 {
+			phases = append(phases, stmts)
+		}
+	}
+	if len(phases) == 0 && len(m.enums) == 0 {
+		_, err = fmt.Fprintln(w, "-- no changes")
+		return
+	}
+
+	if len(m.enums) > 0 {
+		_, err = fmt.Fprintf(w, "-- new enum values cannot be used in the transaction that adds them\n%s\n\n", strings.Join(m.enums, "\n"))
+		if err != nil {
+			return
+		}
+	}
+	if len(phases) == 0 {
+		return
+	}
+
+	_, err = fmt.Fprint(w, "BEGIN;\n\n")
+	for _, stmts := range phases {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%s\n\n", strings.Join(stmts, "\n"))
+		}
+	}
+	if err == nil {
+		_, err = fmt.Fprintln(w, "COMMIT;")
+	}
+	return
+}
+
+func (m *migration) schemas(from *db.Model, added []*db.TableInfo) {
+	known := map[string]bool{"public": true}
+	for _, info := range from.Tables {
+		known[info.Schema] = true
+	}
+	var schemas []string
+	for _, info := range added {
+		if !known[info.Schema] {
+			known[info.Schema] = true
+			schemas = append(schemas, info.Schema)
+		}
+	}
+	sort.Strings(schemas)
+	for _, schema := range schemas {
+		m.add("CREATE SCHEMA IF NOT EXISTS %s;", Ident(schema))
+	}
+}
+
+func (m *migration) userTypes(from *db.Model, to *db.Model) {
+	if to.Types == nil {
+		return
+	}
+	old := from.Types
+	if old == nil {
+		old = &db.UserTypes{}
+	}
+
+	used := map[string]bool{}
+	for _, info := range to.Tables {
+		for _, c := range info.Columns {
+			used[tableKey(c.DomainSchema, c.DomainName)] = true
+			used[tableKey(c.UdtSchema, strings.TrimPrefix(c.UdtName, "_"))] = true
+		}
+	}
+
+	for _, key := range sortedKeys(to.Types.Enums) {
+		enum := to.Types.Enums[key]
+		prev, ok := old.Enums[key]
+		if !ok {
+			if used[key] {
+				m.add("%s", CreateEnum(enum))
+			}
+			continue
+		}
+		for i, label := range enum.Labels {
+			if slices.Contains(prev.Labels, label) {
+				continue
+			}
+			position := ""
+			if i > 0 {
+				position = " AFTER " + Literal(enum.Labels[i-1])
+			} else if len(enum.Labels) > 1 {
+				position = " BEFORE " + Literal(enum.Labels[1])
+			}
+			m.enums = append(m.enums, fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s%s;", Qualified(enum.Schema, enum.Name), Literal(label), position))
+		}
+		for _, label := range prev.Labels {
+			if !slices.Contains(enum.Labels, label) {
+				m.enums = append(m.enums, fmt.Sprintf("-- enum %s: value %s was removed; PostgreSQL cannot drop enum values", Qualified(enum.Schema, enum.Name), Literal(label)))
+			}
+		}
+	}
+	for _, key := range sortedKeys(to.Types.Domains) {
+		if _, ok := old.Domains[key]; !ok && used[key] {
+			m.add("%s", CreateDomain(to.Types.Domains[key]))
+		}
+	}
+}
+
+func (m *migration) columns(td *diff.TableDiff) {
+	table := Qualified(td.Schema, td.Name)
+	if label, ok := kindLabels[td.New.Kind]; ok {
+		if len(td.Columns) > 0 {
+			m.add("-- %s %s changed: definition not available", label, table)
+		}
+		return
+	}
+	for _, cd := range td.Columns {
+		column := Ident(cd.Name)
+		switch cd.Status {
+		case diff.Added:
+			m.add("ALTER TABLE %s ADD COLUMN %s;", table, ColumnDefinition(td.New, cd.New))
+		case diff.Changed:
+			if cd.Type != nil {
+				typ := ColumnType(cd.New)
+				m.destroy("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", table, column, typ, column, typ)
+			}
+			if cd.Default != nil {
+				if len(cd.Default.New) == 0 {
+					m.add("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column)
+				} else {
+					m.add("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, cd.Default.New)
+				}
+			}
+			if cd.Nullable != nil {
+				if cd.Nullable.New == "NO" {
+					m.add("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", table, column)
+				} else {
+					m.add("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", table, column)
+				}
+			}
+		}
+	}
+}
+
+func (m *migration) comments(td *diff.TableDiff) {
+	table := Qualified(td.Schema, td.Name)
+	if td.Comment != nil {
+		m.add("%s", Comment(objectKind(td.New), table, td.Comment.New))
+	}
+	for _, cd := range td.Columns {
+		switch {
+		case cd.Status == diff.Added && len(cd.New.Comment) > 0:
+			m.add("%s", Comment("COLUMN", table+"."+Ident(cd.Name), cd.New.Comment))
+		case cd.Status == diff.Changed && cd.Comment != nil:
+			m.add("%s", Comment("COLUMN", table+"."+Ident(cd.Name), cd.Comment.New))
+		}
+	}
+}
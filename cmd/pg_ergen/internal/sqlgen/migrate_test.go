@@ -0,0 +1,158 @@
+package sqlgen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func snapshotFile(t *testing.T, path string) (model db.Model) {
+	t.Helper()
+	model, err := (&db.DDLSource{Path: path}).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func migrationSQL(t *testing.T, from *db.Model, to *db.Model, destructive bool) string {
+	t.Helper()
+	d := diff.Compare(from, to)
+	var sb strings.Builder
+	if err := WriteMigration(&sb, from, to, &d, destructive); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestWriteMigrationGolden(t *testing.T) {
+	tests := []struct {
+		from        string
+		to          string
+		golden      string
+		destructive bool
+	}{
+		{"migrate_from.sql", "migrate_to.sql", "migrate.golden", false},
+		{"migrate_from.sql", "migrate_to.sql", "migrate_destructive.golden", true},
+		{"migrate_keys_from.sql", "migrate_keys_to.sql", "migrate_keys.golden", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			from := snapshotFile(t, filepath.Join("testdata", tt.from))
+			to := snapshotFile(t, filepath.Join("testdata", tt.to))
+			got := migrationSQL(t, &from, &to, tt.destructive)
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("migration differs from %s:\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestWriteMigrationDestructive(t *testing.T) {
+	from := snapshotFile(t, filepath.Join("testdata", "migrate_from.sql"))
+	to := snapshotFile(t, filepath.Join("testdata", "migrate_to.sql"))
+
+	statements := []string{
+		"ALTER TABLE public.users ALTER COLUMN name TYPE text USING name::text;",
+		"ALTER TABLE public.users DROP COLUMN tags;",
+		"DROP TABLE public.legacy_child;",
+		"DROP TABLE public.legacy_parent;",
+	}
+	safe := strings.Split(migrationSQL(t, &from, &to, false), "\n")
+	destructive := strings.Split(migrationSQL(t, &from, &to, true), "\n")
+	for _, stmt := range statements {
+		if !slices.Contains(safe, "-- "+stmt) || slices.Contains(safe, stmt) {
+			t.Errorf("%q is not commented out without -destructive", stmt)
+		}
+		if !slices.Contains(destructive, stmt) {
+			t.Errorf("%q is missing with -destructive", stmt)
+		}
+	}
+}
+
+func TestWriteMigrationNoChanges(t *testing.T) {
+	model := snapshotFile(t, filepath.Join("testdata", "migrate_from.sql"))
+	if got := migrationSQL(t, &model, &model, true); got != "-- no changes\n" {
+		t.Errorf("migration = %q, want no changes", got)
+	}
+}
+
+func TestWriteMigrationView(t *testing.T) {
+	view := func(columns ...string) db.Model {
+		info := db.TableInfo{Schema: "public", Name: "v", Kind: db.KindView, Columns: db.Columns{}}
+		for i, name := range columns {
+			info.Columns[name] = &db.Column{TableSchema: "public", TableName: "v", ColumnName: name, OrdinalPosition: i + 1, DataType: "integer", IsNullable: "YES"}
+		}
+		return db.Model{Tables: []db.TableInfo{info}}
+	}
+	from := view("a", "b")
+	to := view("a", "c")
+	to.Tables[0].Comment = "recent rows"
+
+	got := migrationSQL(t, &from, &to, true)
+	want := `BEGIN;
+
+-- view public.v changed: definition not available
+
+COMMENT ON VIEW public.v IS 'recent rows';
+
+COMMIT;
+`
+	if got != want {
+		t.Errorf("migration:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	model := loadDDL(t, `
+		CREATE TABLE child (id int PRIMARY KEY, parent_id int REFERENCES parent, a_id int);
+		CREATE TABLE parent (id int PRIMARY KEY);
+		CREATE TABLE a (id int PRIMARY KEY, b_id int);
+		CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a);
+		CREATE TABLE self (id int PRIMARY KEY, parent_id int REFERENCES self);
+		CREATE TABLE part (id int, at date) PARTITION BY RANGE (at);
+		CREATE TABLE part_2024 PARTITION OF part FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+		ALTER TABLE a ADD CONSTRAINT a_b_fk FOREIGN KEY (b_id) REFERENCES b;
+		ALTER TABLE child ADD CONSTRAINT child_a_fk FOREIGN KEY (a_id) REFERENCES a;
+	`)
+	var infos []*db.TableInfo
+	for i := range model.Tables {
+		infos = append(infos, &model.Tables[i])
+	}
+
+	position := map[string]int{}
+	for i, info := range DependencyOrder(infos) {
+		position[info.Name] = i
+	}
+	if len(position) != len(infos) {
+		t.Fatalf("DependencyOrder returned %d tables, want %d", len(position), len(infos))
+	}
+	before := [][2]string{
+		{"parent", "child"},
+		{"a", "child"},
+		{"part", "part_2024"},
+	}
+	for _, pair := range before {
+		if position[pair[0]] > position[pair[1]] {
+			t.Errorf("%s is ordered after %s", pair[0], pair[1])
+		}
+	}
+}
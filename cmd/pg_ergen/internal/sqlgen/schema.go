@@ -0,0 +1,282 @@
+package sqlgen
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+var kindLabels = map[string]string{
+	db.KindView:             "view",
+	db.KindMaterializedView: "materialized view",
+	db.KindForeignTable:     "foreign table",
+}
+
+func tableKey(schema string, n string) string {
+	return schema + "." + n
+}
+
+func SortedColumns(info *db.TableInfo) (columns []*db.Column) {
+	for _, column := range info.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].OrdinalPosition < columns[j].OrdinalPosition
+	})
+	return
+}
+
+func parents(info *db.TableInfo) (names []db.TableName) {
+	if info.PartitionOf != nil {
+		names = append(names, *info.PartitionOf)
+	}
+	return append(names, info.Inherits...)
+}
+
+func DependencyOrder(tables []*db.TableInfo) (ordered []*db.TableInfo) {
+	byKey := map[string]*db.TableInfo{}
+	for _, info := range tables {
+		byKey[tableKey(info.Schema, info.Name)] = info
+	}
+
+	state := map[string]int{}
+	var visit func(info *db.TableInfo)
+	visit = func(info *db.TableInfo) {
+		key := tableKey(info.Schema, info.Name)
+		if state[key] != 0 {
+			return
+		}
+		state[key] = 1
+		for _, tn := range parents(info) {
+			if parent, ok := byKey[tableKey(tn.Schema, tn.Name)]; ok {
+				visit(parent)
+			}
+		}
+		for _, fk := range info.ForeignKeys {
+			if target, ok := byKey[tableKey(fk.TableSchema, fk.TableName)]; ok {
+				visit(target)
+			}
+		}
+		state[key] = 2
+		ordered = append(ordered, info)
+	}
+	for _, info := range tables {
+		visit(info)
+	}
+	return
+}
+
+type schemaWriter struct {
+	w       io.Writer
+	err     error
+	types   *db.UserTypes
+	tables  map[string]bool
+	created map[string]bool
+	pending []string
+}
+
+func (sw *schemaWriter) printf(format string, args ...any) {
+	if sw.err == nil {
+		_, sw.err = fmt.Fprintf(sw.w, format, args...)
+	}
+}
+
+func WriteSchema(w io.Writer, types *db.UserTypes, tables []db.TableInfo) error {
+	sw := &schemaWriter{w: w, types: types, tables: map[string]bool{}, created: map[string]bool{}}
+
+	infos := make([]*db.TableInfo, len(tables))
+	for i := range tables {
+		infos[i] = &tables[i]
+		sw.tables[tableKey(tables[i].Schema, tables[i].Name)] = true
+	}
+	infos = DependencyOrder(infos)
+
+	sw.schemas(infos)
+	sw.userTypes(infos)
+	sw.sequences(infos)
+	for _, info := range infos {
+		sw.table(info)
+	}
+	if len(sw.pending) > 0 {
+		for _, stmt := range sw.pending {
+			sw.printf("%s\n", stmt)
+		}
+		sw.printf("\n")
+	}
+
+	return sw.err
+}
+
+func (sw *schemaWriter) schemas(infos []*db.TableInfo) {
+	var schemas []string
+	for _, info := range infos {
+		if info.Schema != "public" && !slices.Contains(schemas, info.Schema) {
+			schemas = append(schemas, info.Schema)
+		}
+	}
+	sort.Strings(schemas)
+	for _, schema := range schemas {
+		sw.printf("CREATE SCHEMA IF NOT EXISTS %s;\n", Ident(schema))
+	}
+	if len(schemas) > 0 {
+		sw.printf("\n")
+	}
+}
+
+func (sw *schemaWriter) userTypes(infos []*db.TableInfo) {
+	if sw.types == nil {
+		return
+	}
+
+	enums := map[string]bool{}
+	domains := map[string]bool{}
+	for _, info := range infos {
+		for _, c := range info.Columns {
+			if len(c.DomainName) > 0 {
+				domains[tableKey(c.DomainSchema, c.DomainName)] = true
+			}
+			enums[tableKey(c.UdtSchema, strings.TrimPrefix(c.UdtName, "_"))] = true
+		}
+	}
+
+	var stmts []string
+	for _, key := range sortedKeys(sw.types.Enums) {
+		if enum := sw.types.Enums[key]; enums[key] {
+			stmts = append(stmts, CreateEnum(enum))
+			if len(enum.Comment) > 0 {
+				stmts = append(stmts, Comment("TYPE", Qualified(enum.Schema, enum.Name), enum.Comment))
+			}
+		}
+	}
+	for _, key := range sortedKeys(sw.types.Domains) {
+		if domain := sw.types.Domains[key]; domains[key] {
+			stmts = append(stmts, CreateDomain(domain))
+			if len(domain.Comment) > 0 {
+				stmts = append(stmts, Comment("DOMAIN", Qualified(domain.Schema, domain.Name), domain.Comment))
+			}
+		}
+	}
+	for _, stmt := range stmts {
+		sw.printf("%s\n", stmt)
+	}
+	if len(stmts) > 0 {
+		sw.printf("\n")
+	}
+}
+
+func (sw *schemaWriter) sequences(infos []*db.TableInfo) {
+	var sequences []string
+	for _, info := range infos {
+		for _, c := range info.Columns {
+			m := nextvalDefault.FindStringSubmatch(c.ColumnDefault)
+			if m != nil && !isSerial(info, c) && !slices.Contains(sequences, m[1]) {
+				sequences = append(sequences, m[1])
+			}
+		}
+	}
+	sort.Strings(sequences)
+	for _, sequence := range sequences {
+		sw.printf("CREATE SEQUENCE IF NOT EXISTS %s;\n", strings.ReplaceAll(sequence, "''", "'"))
+	}
+	if len(sequences) > 0 {
+		sw.printf("\n")
+	}
+}
+
+func sortedKeys[T any](m map[string]T) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func (sw *schemaWriter) table(info *db.TableInfo) {
+	table := Qualified(info.Schema, info.Name)
+	columns := SortedColumns(info)
+	sw.created[tableKey(info.Schema, info.Name)] = true
+
+	if label, ok := kindLabels[info.Kind]; ok {
+		sw.printf("-- %s %s: definition not available\n", label, table)
+		for _, stmt := range TableComments(info, columns) {
+			sw.printf("-- %s\n", stmt)
+		}
+		sw.printf("\n")
+		return
+	}
+
+	if info.PartitionOf != nil {
+		sw.printf("CREATE TABLE %s PARTITION OF %s", table, Qualified(info.PartitionOf.Schema, info.PartitionOf.Name))
+		if len(info.PartitionBound) > 0 {
+			sw.printf(" %s", info.PartitionBound)
+		}
+		if len(info.PartitionKey) > 0 {
+			sw.printf(" PARTITION BY %s", info.PartitionKey)
+		}
+		sw.printf(";\n")
+		for _, stmt := range TableComments(info, columns) {
+			sw.printf("%s\n", stmt)
+		}
+		sw.printf("\n")
+		return
+	}
+
+	var lines []string
+	for _, c := range columns {
+		if !c.IsInherited {
+			lines = append(lines, ColumnDefinition(info, c))
+		}
+	}
+	if len(info.PrimaryKey) > 0 {
+		lines = append(lines, "CONSTRAINT "+Ident(info.PrimaryKeyName)+" "+PrimaryKeyDefinition(info))
+	}
+	for _, key := range info.UniqueKeys {
+		if !key.IsIndex {
+			lines = append(lines, "CONSTRAINT "+Ident(key.Name)+" "+UniqueDefinition(key))
+		}
+	}
+	for _, con := range info.Constraints {
+		lines = append(lines, "CONSTRAINT "+Ident(con.Name)+" "+ConstraintDefinition(con))
+	}
+	for _, fk := range info.ForeignKeys {
+		def := "CONSTRAINT " + Ident(fk.ConstraintName) + " " + ForeignKeyDefinition(fk)
+		target := tableKey(fk.TableSchema, fk.TableName)
+		switch {
+		case sw.created[target]:
+			lines = append(lines, def)
+		case sw.tables[target]:
+			sw.pending = append(sw.pending, "ALTER TABLE "+table+" ADD "+def+";")
+		default:
+			sw.pending = append(sw.pending, "-- "+Qualified(fk.TableSchema, fk.TableName)+" is not exported",
+				"-- ALTER TABLE "+table+" ADD "+def+";")
+		}
+	}
+
+	sw.printf("CREATE TABLE %s (\n\t%s\n)", table, strings.Join(lines, ",\n\t"))
+	if len(info.Inherits) > 0 {
+		var names []string
+		for _, tn := range info.Inherits {
+			names = append(names, Qualified(tn.Schema, tn.Name))
+		}
+		sw.printf(" INHERITS (%s)", strings.Join(names, ", "))
+	}
+	if len(info.PartitionKey) > 0 {
+		sw.printf(" PARTITION BY %s", info.PartitionKey)
+	}
+	sw.printf(";\n")
+
+	for _, ix := range info.Indexes {
+		if ix.IsPrimary || slices.ContainsFunc(info.UniqueKeys, func(key *db.UniqueKey) bool { return key.Name == ix.Name && !key.IsIndex }) {
+			continue
+		}
+		sw.printf("%s\n", CreateIndex(info, ix))
+	}
+	for _, stmt := range TableComments(info, columns) {
+		sw.printf("%s\n", stmt)
+	}
+	sw.printf("\n")
+}
@@ -0,0 +1,90 @@
+package sqlgen
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+)
+
+func loadDDL(t *testing.T, src string) (model db.Model) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	model, err := (&db.DDLSource{Path: path}).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestWriteSchema(t *testing.T) {
+	model := loadDDL(t, `
+		CREATE TABLE a (id int PRIMARY KEY, b_id int, tags varchar(20)[]);
+		CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a, y_id int);
+		CREATE TABLE y (id int PRIMARY KEY);
+		ALTER TABLE a ADD CONSTRAINT a_b_fk FOREIGN KEY (b_id) REFERENCES b (id);
+		ALTER TABLE b ADD CONSTRAINT b_y_fk FOREIGN KEY (y_id) REFERENCES y (id);
+	`)
+	tables := slices.DeleteFunc(model.Tables, func(info db.TableInfo) bool { return info.Name == "y" })
+
+	var sb strings.Builder
+	if err := WriteSchema(&sb, model.Types, tables); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `CREATE TABLE public.b (
+	id integer NOT NULL,
+	a_id integer,
+	y_id integer,
+	CONSTRAINT b_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.a (
+	id integer NOT NULL,
+	b_id integer,
+	tags varchar(20)[],
+	CONSTRAINT a_pkey PRIMARY KEY (id),
+	CONSTRAINT a_b_fk FOREIGN KEY (b_id) REFERENCES public.b (id)
+);
+
+ALTER TABLE public.b ADD CONSTRAINT b_a_id_fkey FOREIGN KEY (a_id) REFERENCES public.a (id);
+-- public.y is not exported
+-- ALTER TABLE public.b ADD CONSTRAINT b_y_fk FOREIGN KEY (y_id) REFERENCES public.y (id);
+
+`
+	if got := sb.String(); got != want {
+		t.Errorf("WriteSchema:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteSchemaRoundTrip(t *testing.T) {
+	files := []string{
+		filepath.Join("..", "db", "testdata", "pg_dump.sql"),
+		filepath.Join("testdata", "migrate_from.sql"),
+		filepath.Join("testdata", "migrate_to.sql"),
+	}
+	for _, path := range files {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			model := snapshotFile(t, path)
+			var sb strings.Builder
+			if err := WriteSchema(&sb, model.Types, model.Tables); err != nil {
+				t.Fatal(err)
+			}
+			exported := loadDDL(t, sb.String())
+
+			d := diff.Compare(&model, &exported)
+			if !d.Empty() {
+				var text strings.Builder
+				d.WriteText(&text)
+				t.Errorf("exported schema differs:\n%s\nexport:\n%s", text.String(), sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package sqlgen
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+var (
+	plainIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+	nextvalDefault  = regexp.MustCompile(`^nextval\('((?:[^']|'')+)'::regclass\)$`)
+)
+
+var reservedWords = []string{
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "authorization",
+	"binary", "both", "case", "cast", "check", "collate", "collation", "column", "concurrently",
+	"constraint", "create", "cross", "current_catalog", "current_date", "current_role",
+	"current_schema", "current_time", "current_timestamp", "current_user", "default", "deferrable",
+	"desc", "distinct", "do", "else", "end", "except", "false", "fetch", "for", "foreign", "freeze",
+	"from", "full", "grant", "group", "having", "ilike", "in", "initially", "inner", "intersect",
+	"into", "is", "isnull", "join", "lateral", "leading", "left", "like", "limit", "localtime",
+	"localtimestamp", "natural", "not", "notnull", "null", "offset", "on", "only", "or", "order",
+	"outer", "overlaps", "placing", "primary", "references", "returning", "right", "select",
+	"session_user", "similar", "some", "symmetric", "system_user", "table", "tablesample", "then",
+	"to", "trailing", "true", "union", "unique", "user", "using", "variadic", "verbose", "when",
+	"where", "window", "with",
+}
+
+var serialTypes = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
+
+func Ident(name string) string {
+	if plainIdentifier.MatchString(name) && !slices.Contains(reservedWords, name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func Qualified(schema string, name string) string {
+	return Ident(schema) + "." + Ident(name)
+}
+
+func Literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func identList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = Ident(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func isSerial(info *db.TableInfo, c *db.Column) bool {
+	if _, ok := serialTypes[c.DataType]; !ok {
+		return false
+	}
+	return c.ColumnDefault == db.SerialDefault(info.Schema, info.Name, c.ColumnName)
+}
+
+func ColumnType(c *db.Column) string {
+	if len(c.DomainName) > 0 {
+		return Qualified(c.DomainSchema, c.DomainName)
+	}
+	if c.DataType == "USER-DEFINED" {
+		return Qualified(c.UdtSchema, c.UdtName)
+	}
+	if c.DataType == "ARRAY" && c.UdtSchema != "pg_catalog" && len(c.UdtSchema) > 0 {
+		return Qualified(c.UdtSchema, strings.TrimPrefix(c.UdtName, "_")) + "[]"
+	}
+	return c.FormatType(nil)
+}
+
+func ColumnDefinition(info *db.TableInfo, c *db.Column) string {
+	if isSerial(info, c) {
+		return Ident(c.ColumnName) + " " + serialTypes[c.DataType] + " NOT NULL"
+	}
+
+	def := Ident(c.ColumnName) + " " + ColumnType(c)
+	switch {
+	case c.IsIdentity == "YES":
+		generation := c.IdentityGeneration
+		if len(generation) == 0 {
+			generation = "BY DEFAULT"
+		}
+		def += " GENERATED " + generation + " AS IDENTITY"
+	case c.IsGenerated == "ALWAYS":
+		def += " GENERATED ALWAYS AS (" + c.GenerationExpression + ") STORED"
+	case len(c.ColumnDefault) > 0:
+		def += " DEFAULT " + c.ColumnDefault
+	}
+	if c.IsNullable == "NO" {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+func deferrable(def string, isDeferrable bool, initiallyDeferred bool) string {
+	if !isDeferrable || strings.Contains(def, "DEFERRABLE") {
+		return def
+	}
+	def += " DEFERRABLE"
+	if initiallyDeferred {
+		def += " INITIALLY DEFERRED"
+	}
+	return def
+}
+
+func ForeignKeyDefinition(fk *db.ForeignKey) string {
+	def := "FOREIGN KEY (" + identList(fk.Columns) + ") REFERENCES " + Qualified(fk.TableSchema, fk.TableName)
+	if len(fk.ReferencedColumns) > 0 {
+		def += " (" + identList(fk.ReferencedColumns) + ")"
+	}
+	if len(fk.MatchOption) > 0 && fk.MatchOption != "NONE" {
+		def += " MATCH " + fk.MatchOption
+	}
+	if len(fk.UpdateRule) > 0 && fk.UpdateRule != "NO ACTION" {
+		def += " ON UPDATE " + fk.UpdateRule
+	}
+	if len(fk.DeleteRule) > 0 && fk.DeleteRule != "NO ACTION" {
+		def += " ON DELETE " + fk.DeleteRule
+	}
+	return deferrable(def, fk.IsDeferrable, fk.InitiallyDeferred)
+}
+
+func ConstraintDefinition(con *db.Constraint) string {
+	return deferrable(con.Definition, con.IsDeferrable, con.InitiallyDeferred)
+}
+
+func PrimaryKeyDefinition(info *db.TableInfo) string {
+	return "PRIMARY KEY (" + identList(info.PrimaryKey) + ")"
+}
+
+func UniqueDefinition(key *db.UniqueKey) string {
+	return "UNIQUE (" + identList(key.Columns) + ")"
+}
+
+func CreateIndex(info *db.TableInfo, ix *db.Index) string {
+	stmt := "CREATE "
+	if ix.IsUnique {
+		stmt += "UNIQUE "
+	}
+	stmt += "INDEX " + Ident(ix.Name) + " ON " + Qualified(info.Schema, info.Name)
+	if len(ix.Method) > 0 && ix.Method != "btree" {
+		stmt += " USING " + ix.Method
+	}
+	columns := make([]string, len(ix.Columns))
+	for i, column := range ix.Columns {
+		columns[i] = column
+		if _, ok := info.Columns[column]; ok {
+			columns[i] = Ident(column)
+		}
+	}
+	stmt += " (" + strings.Join(columns, ", ") + ")"
+	if len(ix.Predicate) > 0 {
+		stmt += " WHERE " + ix.Predicate
+	}
+	return stmt + ";"
+}
+
+func CreateEnum(enum *db.Enum) string {
+	labels := make([]string, len(enum.Labels))
+	for i, label := range enum.Labels {
+		labels[i] = Literal(label)
+	}
+	return "CREATE TYPE " + Qualified(enum.Schema, enum.Name) + " AS ENUM (" + strings.Join(labels, ", ") + ");"
+}
+
+func CreateDomain(domain *db.Domain) string {
+	stmt := "CREATE DOMAIN " + Qualified(domain.Schema, domain.Name) + " AS " + domain.BaseType
+	if len(domain.Default) > 0 {
+		stmt += " DEFAULT " + domain.Default
+	}
+	if domain.NotNull {
+		stmt += " NOT NULL"
+	}
+	for _, constraint := range domain.Constraints {
+		stmt += " " + constraint
+	}
+	return stmt + ";"
+}
+
+func Comment(kind string, target string, text string) string {
+	value := "NULL"
+	if len(text) > 0 {
+		value = Literal(text)
+	}
+	return "COMMENT ON " + kind + " " + target + " IS " + value + ";"
+}
+
+func objectKind(info *db.TableInfo) string {
+	if label, ok := kindLabels[info.Kind]; ok {
+		return strings.ToUpper(label)
+	}
+	return "TABLE"
+}
+
+func TableComments(info *db.TableInfo, columns []*db.Column) (stmts []string) {
+	table := Qualified(info.Schema, info.Name)
+	kind := objectKind(info)
+	if len(info.Comment) > 0 {
+		stmts = append(stmts, Comment(kind, table, info.Comment))
+	}
+	for _, c := range columns {
+		if len(c.Comment) > 0 && !c.IsInherited {
+			stmts = append(stmts, Comment("COLUMN", table+"."+Ident(c.ColumnName), c.Comment))
+		}
+	}
+	if len(info.PrimaryKeyComment) > 0 {
+		stmts = append(stmts, Comment("CONSTRAINT", Ident(info.PrimaryKeyName)+" ON "+table, info.PrimaryKeyComment))
+	}
+	for _, key := range info.UniqueKeys {
+		if len(key.Comment) > 0 {
+			if key.IsIndex {
+				stmts = append(stmts, Comment("INDEX", Qualified(info.Schema, key.Name), key.Comment))
+			} else {
+				stmts = append(stmts, Comment("CONSTRAINT", Ident(key.Name)+" ON "+table, key.Comment))
+			}
+		}
+	}
+	for _, con := range info.Constraints {
+		if len(con.Comment) > 0 {
+			stmts = append(stmts, Comment("CONSTRAINT", Ident(con.Name)+" ON "+table, con.Comment))
+		}
+	}
+	for _, fk := range info.ForeignKeys {
+		if len(fk.Comment) > 0 {
+			stmts = append(stmts, Comment("CONSTRAINT", Ident(fk.ConstraintName)+" ON "+table, fk.Comment))
+		}
+	}
+	for _, ix := range info.Indexes {
+		if len(ix.Comment) > 0 && !ix.IsPrimary && !slices.ContainsFunc(info.UniqueKeys, func(key *db.UniqueKey) bool { return key.Name == ix.Name }) {
+			stmts = append(stmts, Comment("INDEX", Qualified(info.Schema, ix.Name), ix.Comment))
+		}
+	}
+	return
+}
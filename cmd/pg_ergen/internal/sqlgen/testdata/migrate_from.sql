@@ -0,0 +1,21 @@
+CREATE TYPE order_state AS ENUM ('new', 'paid');
+
+CREATE TABLE users (
+    id serial PRIMARY KEY,
+    email text NOT NULL CONSTRAINT users_email_key UNIQUE,
+    name varchar(80) DEFAULT 'anon',
+    tags text[],
+    created_at timestamptz DEFAULT now()
+);
+COMMENT ON TABLE users IS 'Users';
+
+CREATE TABLE orders (
+    id serial PRIMARY KEY,
+    user_id int NOT NULL,
+    state order_state NOT NULL DEFAULT 'new',
+    CONSTRAINT orders_user_fkey FOREIGN KEY (user_id) REFERENCES users
+);
+CREATE INDEX orders_state_idx ON orders (state);
+
+CREATE TABLE legacy_parent (id int PRIMARY KEY);
+CREATE TABLE legacy_child (id int PRIMARY KEY, parent_id int REFERENCES legacy_parent);
@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id int CONSTRAINT users_pkey PRIMARY KEY,
+    name text,
+    code text CONSTRAINT users_code_key UNIQUE
+);
+
+CREATE TABLE orders (
+    id int PRIMARY KEY,
+    user_id int REFERENCES users (id),
+    user_code text REFERENCES users (code),
+    parent_id int REFERENCES order_groups
+);
+
+CREATE TABLE order_groups (id int PRIMARY KEY);
@@ -0,0 +1,36 @@
+CREATE TYPE order_state AS ENUM ('new', 'paid', 'shipped');
+CREATE TYPE priority AS ENUM ('low', 'high');
+CREATE DOMAIN positive AS numeric CHECK (VALUE > 0);
+
+CREATE TABLE users (
+    id serial PRIMARY KEY,
+    email text NOT NULL,
+    name text NOT NULL,
+    nickname text DEFAULT 'x' NOT NULL,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX users_lower_email_idx ON users (lower(email));
+COMMENT ON TABLE users IS 'Registered users';
+COMMENT ON COLUMN users.nickname IS 'Shown in lists';
+
+CREATE TABLE orders (
+    id serial PRIMARY KEY,
+    user_id int NOT NULL,
+    state order_state NOT NULL DEFAULT 'shipped',
+    total positive,
+    CONSTRAINT orders_user_fkey FOREIGN KEY (user_id) REFERENCES users ON DELETE CASCADE,
+    CONSTRAINT orders_total_check CHECK (total < 1000000)
+);
+
+CREATE SCHEMA billing;
+CREATE TABLE billing.invoices (
+    id int PRIMARY KEY,
+    order_id int REFERENCES orders,
+    last_payment_id int,
+    priority priority
+);
+CREATE TABLE billing.payments (
+    id int PRIMARY KEY,
+    invoice_id int NOT NULL REFERENCES billing.invoices
+);
+ALTER TABLE billing.invoices ADD CONSTRAINT invoices_last_payment_fkey FOREIGN KEY (last_payment_id) REFERENCES billing.payments;
@@ -9,75 +9,269 @@ import (
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/canvas"
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/config"
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/diff"
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/sqlgen"
+)
+
+const (
+	exitChanged = 1
+	exitError   = 2
 )
 
 func main() {
 	conf, err := config.GetConfig()
 	if err != nil {
-		return
+		fatal(err)
+	}
+
+	param := db.DBConnect{
+		Host:           conf.Host,
+		User:           conf.User,
+		Password:       conf.Password,
+		Schemas:        conf.Schemas,
+		ExcludeSchemas: conf.ExcludeSchemas,
+
+		ExpandPartitions: conf.ExpandPartitions,
+		StatementTimeout: conf.StatementTimeout,
+		Backend:          conf.Backend,
+	}
+
+	opts := canvas.Options{
+		Views:   conf.Views,
+		Indexes: conf.Indexes,
+		Enums:   conf.Enums,
+
+		Constraints: conf.Constraints,
+
+		ExpandPartitions: conf.ExpandPartitions,
+		HideInherited:    conf.HideInherited,
+
+		TypeAbbreviations: conf.TypeAbbreviations,
 	}
 
-	param := db.DBConnect{Host: conf.Host, User: conf.User, Password: conf.Password}
+	today := time.Now().Format("2006-01-02_150405")
 
-	if len(conf.Database) > 0 {
-		c := connectDatabase(param, conf.Database)
+	switch conf.Command {
+	case config.CommandSnapshot:
+		model, err := loadSource(param, inputSpec(conf))
+		if err != nil {
+			fatal(err)
+		}
 
-		today := time.Now().Format("2006-01-02_150405")
-		fn := fmt.Sprintf("ER %s %s.svg", conf.Database, today)
+		fn := conf.Output
+		if len(fn) == 0 {
+			fn = fmt.Sprintf("schema %s %s.json", model.Database, today)
+		}
 
 		f, err := os.Create(fn)
 		if err != nil {
-			log.Fatal(err)
+			fatal(err)
 		}
 		defer f.Close()
-		c.OutputSVG(f)
-	} else {
-		_, err = param.Connect()
+		err = model.WriteJSON(f)
+		if err != nil {
+			fatal(err)
+		}
+	case config.CommandDiff:
+		if len(conf.Args) != 2 {
+			fatal("diff requires two sources (database name, postgres:// URL, SQLite file, DDL file or directory, or snapshot .json file)")
+		}
+
+		from, err := loadSource(param, conf.Args[0])
+		if err != nil {
+			fatal(err)
+		}
+		to, err := loadSource(param, conf.Args[1])
 		if err != nil {
-			panic(err.Error())
+			fatal(err)
 		}
 
-		names, err := param.Databasenames()
+		out, err := createOutput(conf.Output)
 		if err != nil {
-			panic(err.Error())
+			fatal(err)
 		}
 
-		server(param, names, conf.AcceptPort)
+		d := diff.Compare(&from, &to)
+		switch conf.Format {
+		case "json":
+			err = d.WriteJSON(out)
+		case "svg":
+			c := canvas.NewDiffCanvas(&to, &d, opts)
+			c.SetCaption(fmt.Sprintf("diff %s → %s", conf.Args[0], conf.Args[1]))
+			c.OutputSVG(out)
+		default:
+			d.WriteText(out)
+		}
+		if e := closeOutput(out); err == nil {
+			err = e
+		}
+		if err != nil {
+			fatal(err)
+		}
+		if !d.Empty() {
+			os.Exit(exitChanged)
+		}
+	case config.CommandExport:
+		model, err := loadSource(param, inputSpec(conf))
+		if err != nil {
+			fatal(err)
+		}
+
+		tables := exportTables(&model, opts)
+		switch conf.Format {
+		case "go":
+			dir := conf.Output
+			if len(dir) == 0 {
+				dir = "models"
+			}
+			err = writeGoModels(dir, tables)
+		case "sql":
+			var out *os.File
+			out, err = createOutput(conf.Output)
+			if err != nil {
+				fatal(err)
+			}
+			err = sqlgen.WriteSchema(out, model.Types, tables)
+			if e := closeOutput(out); err == nil {
+				err = e
+			}
+		}
+		if err != nil {
+			fatal(err)
+		}
+	case config.CommandMigrate:
+		if len(conf.Args) != 2 {
+			fatal("migrate requires two sources (database name, postgres:// URL, SQLite file, DDL file or directory, Go package, or snapshot .json file)")
+		}
+
+		from, err := loadSource(param, conf.Args[0])
+		if err != nil {
+			fatal(err)
+		}
+		to, err := loadSource(param, conf.Args[1])
+		if err != nil {
+			fatal(err)
+		}
+
+		out, err := createOutput(conf.Output)
+		if err != nil {
+			fatal(err)
+		}
+
+		d := diff.Compare(&from, &to)
+		fmt.Fprintf(out, "-- migrate %s → %s\n", conf.Args[0], conf.Args[1])
+		err = sqlgen.WriteMigration(out, &from, &to, &d, conf.AllowDestructive)
+		if e := closeOutput(out); err == nil {
+			err = e
+		}
+		if err != nil {
+			fatal(err)
+		}
+	default:
+		if len(conf.Input) > 0 {
+			model, err := loadSource(param, conf.Input)
+			if err != nil {
+				fatal(err)
+			}
+
+			fn := conf.Output
+			if len(fn) == 0 {
+				fn = fmt.Sprintf("ER %s %s.svg", model.Database, model.SnapshotAt.Format("2006-01-02_150405"))
+			}
+			outputSVG(drawModel(&model, opts), fn)
+		} else if len(conf.Database) > 0 {
+			c, err := connectDatabase(param, conf.Database, opts)
+			if err != nil {
+				fatal(err)
+			}
+
+			fn := conf.Output
+			if len(fn) == 0 {
+				fn = fmt.Sprintf("ER %s %s.svg", conf.Database, today)
+			}
+			outputSVG(c, fn)
+		} else {
+			_, err = param.Connect()
+			if err != nil {
+				panic(err.Error())
+			}
+
+			names, err := param.Databasenames()
+			if err != nil {
+				panic(err.Error())
+			}
+
+			server(param, names, conf.AcceptPort, opts)
+		}
 	}
 }
 
-func connectDatabase(conn db.DBConnect, dbName string) (c *canvas.Canvas) {
-	log.Println("DB: " + dbName)
+func inputSpec(conf config.Config) (spec string) {
+	spec = conf.Input
+	if len(spec) == 0 {
+		spec = conf.Database
+	}
+	if len(spec) == 0 {
+		fatalf("%s requires a database name (-d) or a source (-i)", conf.Command)
+	}
+	return
+}
 
-	conn.Dbname = dbName
+func fatal(v ...any) {
+	log.Print(v...)
+	os.Exit(exitError)
+}
 
-	_, err := conn.Connect()
-	if err != nil {
-		log.Println(err.Error())
-		return
+func fatalf(format string, v ...any) {
+	log.Printf(format, v...)
+	os.Exit(exitError)
+}
+
+func createOutput(fn string) (out *os.File, err error) {
+	if len(fn) == 0 {
+		return os.Stdout, nil
 	}
+	return os.Create(fn)
+}
 
-	tableNames, err := conn.Tablenames()
-	if err != nil {
-		log.Println(err.Error())
-		return
+func closeOutput(out *os.File) (err error) {
+	if out != os.Stdout {
+		err = out.Close()
 	}
+	return
+}
 
-	tableInfos := []db.TableInfo{}
-	for _, tableName := range tableNames {
-		info, err := conn.GetTableInfo(tableName)
-		if err != nil {
-			log.Println(err.Error())
-			continue
-		}
+func outputSVG(c *canvas.Canvas, fn string) {
+	f, err := os.Create(fn)
+	if err != nil {
+		fatal(err)
+	}
+	defer f.Close()
+	c.OutputSVG(f)
+}
 
-		tableInfos = append(tableInfos, info)
+func drawModel(model *db.Model, opts canvas.Options) (c *canvas.Canvas) {
+	c = canvas.NewCanvas(opts)
+	caption := fmt.Sprintf("%s snapshot %s", model.Database, model.SnapshotAt.Format(time.RFC3339))
+	if len(model.WalLSN) > 0 {
+		caption += ", LSN " + model.WalLSN
+	}
+	c.SetCaption(caption)
+	for _, info := range model.Tables {
+		c.RegisterEntity(canvas.NewEntityFromTableInfo(&info, opts))
 	}
 
-	c = canvas.NewCanvas()
-	for _, info := range tableInfos {
-		c.RegisterEntity(canvas.NewEntityFromTableInfo(&info))
+	return
+}
+
+func connectDatabase(conn db.DBConnect, dbName string, opts canvas.Options) (c *canvas.Canvas, err error) {
+	conn.Dbname = dbName
+
+	model, err := loadModel(&conn)
+	if err != nil {
+		return
 	}
 
+	c = drawModel(&model, opts)
 	return
 }
@@ -9,6 +9,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/canvas"
 	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
 )
 
@@ -53,7 +54,7 @@ var pageTemplate = `
 </script>
 `
 
-func server(conn db.DBConnect, names []string, acceptPort uint16) {
+func server(conn db.DBConnect, names []string, acceptPort uint16, opts canvas.Options) {
 	indexPage := "<ul>"
 	for i := range names {
 		indexPage += fmt.Sprintf(`<li><a href="#" onclick="javascript:onClick('%s')">%s</a></li>`, names[i], names[i])
@@ -71,8 +72,13 @@ func server(conn db.DBConnect, names []string, acceptPort uint16) {
 		if strings.HasPrefix(path, "/") {
 			filename := path[1:]
 			if slices.Contains(names, filename) {
+				c, err := connectDatabase(conn, filename, opts)
+				if err != nil {
+					log.Println(err.Error())
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
 				w.Header().Set("Content-Type", "image/svg+xml")
-				c := connectDatabase(conn, filename)
 				c.OutputSVG(w)
 				return
 			}
@@ -85,7 +91,7 @@ func server(conn db.DBConnect, names []string, acceptPort uint16) {
 	log.Println("Connection: " + url)
 	err := http.ListenAndServe(fmt.Sprintf(":%d", acceptPort), nil)
 	if err != nil {
-		log.Fatal("ListenAndServe:", err)
+		fatal("ListenAndServe:", err)
 	}
 }
 
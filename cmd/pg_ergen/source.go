@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emurenMRz/ergen_go/cmd/pg_ergen/internal/db"
+)
+
+var sqliteExtensions = []string{".db", ".sqlite", ".sqlite3"}
+
+func openSource(conn db.DBConnect, spec string) (source db.SchemaSource, err error) {
+	if strings.HasSuffix(spec, ".json") {
+		return &db.SnapshotFile{Path: spec}, nil
+	}
+	if strings.HasPrefix(spec, "sqlite:") {
+		return &db.SQLiteSource{Path: strings.TrimPrefix(spec, "sqlite:")}, nil
+	}
+	if strings.HasPrefix(spec, "gorm:") {
+		return &db.GormSource{Path: strings.TrimPrefix(spec, "gorm:")}, nil
+	}
+	if strings.HasSuffix(spec, "/...") || hasFiles(spec, "*.go") && !hasFiles(spec, "*.sql") {
+		return &db.GormSource{Path: spec}, nil
+	}
+	if stat, e := os.Stat(spec); strings.HasSuffix(spec, ".sql") || (e == nil && stat.IsDir()) {
+		return &db.DDLSource{Path: spec}, nil
+	}
+	for _, ext := range sqliteExtensions {
+		if strings.HasSuffix(spec, ext) {
+			return &db.SQLiteSource{Path: spec}, nil
+		}
+	}
+
+	conn.Dbname = spec
+	if strings.HasPrefix(spec, "postgres://") || strings.HasPrefix(spec, "postgresql://") {
+		var u *url.URL
+		u, err = url.Parse(spec)
+		if err != nil {
+			return
+		}
+		if len(u.Hostname()) > 0 {
+			conn.Host = u.Hostname()
+		}
+		if len(u.Port()) > 0 {
+			var port int
+			port, err = strconv.Atoi(u.Port())
+			if err != nil {
+				return
+			}
+			conn.Port = uint16(port)
+		}
+		if u.User != nil {
+			conn.User = u.User.Username()
+			if pw, ok := u.User.Password(); ok {
+				conn.Password = pw
+			}
+		}
+		conn.Dbname = strings.TrimPrefix(u.Path, "/")
+	}
+
+	return &conn, nil
+}
+
+func hasFiles(dir string, pattern string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, pattern))
+	return len(files) > 0
+}
+
+func loadSource(conn db.DBConnect, spec string) (model db.Model, err error) {
+	source, err := openSource(conn, spec)
+	if err != nil {
+		return
+	}
+	model, err = loadModel(source)
+	if _, ok := source.(*db.DBConnect); !ok && err == nil {
+		model.FilterSchemas(conn.Schemas, conn.ExcludeSchemas)
+	}
+	return
+}
+
+func loadModel(source db.SchemaSource) (model db.Model, err error) {
+	start := time.Now()
+	model, err = source.Snapshot()
+	if err != nil {
+		return
+	}
+	log.Printf("%s: %d tables introspected in %s", model.Database, len(model.Tables), time.Since(start))
+
+	return
+}